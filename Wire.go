@@ -41,13 +41,61 @@ func InitializeApp() (*App, error) {
 		//logger.NewHttpClient,
 		api.NewRestHandlerImpl,
 		wire.Bind(new(api.RestHandler), new(*api.RestHandlerImpl)),
+		api.NewAdminRestHandlerImpl,
+		wire.Bind(new(api.AdminRestHandler), new(*api.AdminRestHandlerImpl)),
 		pkg.NewReleaseNoteServiceImpl,
 		wire.Bind(new(pkg.ReleaseNoteService), new(*pkg.ReleaseNoteServiceImpl)),
 		pkg.NewWebhookSecretValidatorImpl,
 		wire.Bind(new(pkg.WebhookSecretValidator), new(*pkg.WebhookSecretValidatorImpl)),
 		util.NewModuleConfig,
 		util.NewBlobConfig,
+		util.NewReleaseChannelConfig,
+		pkg.NewReleaseChannelServiceImpl,
+		wire.Bind(new(pkg.ReleaseChannelService), new(*pkg.ReleaseChannelServiceImpl)),
+		pkg.NewReleaseNoteRendererImpl,
+		wire.Bind(new(pkg.ReleaseNoteRenderer), new(*pkg.ReleaseNoteRendererImpl)),
+		pkg.NewReleaseSearchServiceImpl,
+		wire.Bind(new(pkg.ReleaseSearchService), new(*pkg.ReleaseSearchServiceImpl)),
+		pkg.NewReleaseFeedServiceImpl,
+		wire.Bind(new(pkg.ReleaseFeedService), new(*pkg.ReleaseFeedServiceImpl)),
+		util.NewReleaseManifestConfig,
+		pkg.NewReleaseManifestServiceImpl,
+		wire.Bind(new(pkg.ReleaseManifestService), new(*pkg.ReleaseManifestServiceImpl)),
+		util.NewReleaseCompareConfig,
+		pkg.NewReleaseCompareServiceImpl,
+		wire.Bind(new(pkg.ReleaseCompareService), new(*pkg.ReleaseCompareServiceImpl)),
+		util.NewAdminConfig,
+		pkg.NewAdminTokenValidatorImpl,
+		wire.Bind(new(pkg.AdminTokenValidator), new(*pkg.AdminTokenValidatorImpl)),
+		util.NewWebhookSubscriptionConfig,
+		pkg.NewWebhookSubscriptionServiceImpl,
+		wire.Bind(new(pkg.WebhookSubscriptionService), new(*pkg.WebhookSubscriptionServiceImpl)),
+		util.NewWebhookRegistrationConfig,
+		pkg.NewWebhookRegistrationServiceImpl,
+		wire.Bind(new(pkg.WebhookRegistrationService), new(*pkg.WebhookRegistrationServiceImpl)),
+		util.NewEventStreamConfig,
+		pkg.NewEventStreamServiceImpl,
+		wire.Bind(new(pkg.EventStreamService), new(*pkg.EventStreamServiceImpl)),
+		util.NewRolloutConfig,
+		pkg.NewRolloutServiceImpl,
+		wire.Bind(new(pkg.RolloutService), new(*pkg.RolloutServiceImpl)),
+		pkg.NewUpgradeCheckServiceImpl,
+		wire.Bind(new(pkg.UpgradeCheckService), new(*pkg.UpgradeCheckServiceImpl)),
+		util.NewSecurityAdvisoryConfig,
+		pkg.NewSecurityAdvisoryServiceImpl,
+		wire.Bind(new(pkg.SecurityAdvisoryService), new(*pkg.SecurityAdvisoryServiceImpl)),
+		util.NewAnnouncementConfig,
+		pkg.NewAnnouncementServiceImpl,
+		wire.Bind(new(pkg.AnnouncementService), new(*pkg.AnnouncementServiceImpl)),
+		util.NewFeatureFlagConfig,
+		pkg.NewFeatureFlagServiceImpl,
+		wire.Bind(new(pkg.FeatureFlagService), new(*pkg.FeatureFlagServiceImpl)),
+		util.NewTelemetryConfig,
+		pkg.NewTelemetrySink,
+		pkg.NewTelemetryServiceImpl,
+		wire.Bind(new(pkg.TelemetryService), new(*pkg.TelemetryServiceImpl)),
 
+		util.NewCiBuildMetadataConfig,
 		pkg.NewCiBuildMetadataServiceImpl,
 		wire.Bind(new(pkg.CiBuildMetadataService), new(*pkg.CiBuildMetadataServiceImpl)),
 	)
@@ -0,0 +1,409 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package api
+
+import (
+	"encoding/json"
+	"github.com/devtron-labs/central-api/common"
+	"github.com/devtron-labs/central-api/internal/util"
+	"github.com/devtron-labs/central-api/pkg"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+// AdminRestHandler serves the apis which change central api state, all of them need the admin token
+type AdminRestHandler interface {
+	GetWebhookSubscriptions(w http.ResponseWriter, r *http.Request)
+	GetWebhookSubscription(w http.ResponseWriter, r *http.Request)
+	CreateWebhookSubscription(w http.ResponseWriter, r *http.Request)
+	UpdateWebhookSubscription(w http.ResponseWriter, r *http.Request)
+	DeleteWebhookSubscription(w http.ResponseWriter, r *http.Request)
+	GetWebhookDeliveries(w http.ResponseWriter, r *http.Request)
+	GetGitHubWebhookStatus(w http.ResponseWriter, r *http.Request)
+	SyncGitHubWebhooks(w http.ResponseWriter, r *http.Request)
+	GetAnnouncements(w http.ResponseWriter, r *http.Request)
+	GetAnnouncement(w http.ResponseWriter, r *http.Request)
+	CreateAnnouncement(w http.ResponseWriter, r *http.Request)
+	UpdateAnnouncement(w http.ResponseWriter, r *http.Request)
+	DeleteAnnouncement(w http.ResponseWriter, r *http.Request)
+	GetVersionAdoption(w http.ResponseWriter, r *http.Request)
+	GetModulePopularity(w http.ResponseWriter, r *http.Request)
+	GetRolloutPolicies(w http.ResponseWriter, r *http.Request)
+	GetRolloutPolicy(w http.ResponseWriter, r *http.Request)
+	SaveRolloutPolicy(w http.ResponseWriter, r *http.Request)
+	DeleteRolloutPolicy(w http.ResponseWriter, r *http.Request)
+	AdvanceRollout(w http.ResponseWriter, r *http.Request)
+	HaltRollout(w http.ResponseWriter, r *http.Request)
+}
+
+type AdminRestHandlerImpl struct {
+	logger                     *zap.SugaredLogger
+	adminTokenValidator        pkg.AdminTokenValidator
+	webhookSubscriptionService pkg.WebhookSubscriptionService
+	webhookRegistrationService pkg.WebhookRegistrationService
+	announcementService        pkg.AnnouncementService
+	telemetryService           pkg.TelemetryService
+	rolloutService             pkg.RolloutService
+}
+
+func NewAdminRestHandlerImpl(logger *zap.SugaredLogger, adminTokenValidator pkg.AdminTokenValidator,
+	webhookSubscriptionService pkg.WebhookSubscriptionService, webhookRegistrationService pkg.WebhookRegistrationService,
+	announcementService pkg.AnnouncementService, telemetryService pkg.TelemetryService,
+	rolloutService pkg.RolloutService) *AdminRestHandlerImpl {
+	return &AdminRestHandlerImpl{
+		logger:                     logger,
+		adminTokenValidator:        adminTokenValidator,
+		webhookSubscriptionService: webhookSubscriptionService,
+		webhookRegistrationService: webhookRegistrationService,
+		announcementService:        announcementService,
+		telemetryService:           telemetryService,
+		rolloutService:             rolloutService,
+	}
+}
+
+// isAuthorized writes 401 and returns false when the request does not carry the admin token
+func (impl *AdminRestHandlerImpl) isAuthorized(w http.ResponseWriter, r *http.Request) bool {
+	if impl.adminTokenValidator.ValidateToken(r) {
+		return true
+	}
+	impl.logger.Warnw("unauthorized admin request", "path", r.URL.Path, "remoteAddr", r.RemoteAddr)
+	writeJsonResp(w, util.NewApiError(http.StatusUnauthorized, "invalid or missing admin token"), nil, http.StatusUnauthorized)
+	return false
+}
+
+func (impl *AdminRestHandlerImpl) GetWebhookSubscriptions(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	writeJsonResp(w, nil, impl.webhookSubscriptionService.GetSubscriptions(), http.StatusOK)
+}
+
+func (impl *AdminRestHandlerImpl) GetWebhookSubscription(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	subscription, err := impl.webhookSubscriptionService.GetSubscription(mux.Vars(r)["id"])
+	if err != nil {
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, subscription, http.StatusOK)
+}
+
+func (impl *AdminRestHandlerImpl) CreateWebhookSubscription(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	request := &common.WebhookSubscriptionRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		writeJsonResp(w, err, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	subscription, err := impl.webhookSubscriptionService.CreateSubscription(request)
+	if err != nil {
+		impl.logger.Errorw("error in creating webhook subscription", "url", request.Url, "err", err)
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, subscription, http.StatusCreated)
+}
+
+func (impl *AdminRestHandlerImpl) UpdateWebhookSubscription(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	request := &common.WebhookSubscriptionRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		writeJsonResp(w, err, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	id := mux.Vars(r)["id"]
+	subscription, err := impl.webhookSubscriptionService.UpdateSubscription(id, request)
+	if err != nil {
+		impl.logger.Errorw("error in updating webhook subscription", "id", id, "err", err)
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, subscription, http.StatusOK)
+}
+
+func (impl *AdminRestHandlerImpl) DeleteWebhookSubscription(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	err := impl.webhookSubscriptionService.DeleteSubscription(id)
+	if err != nil {
+		impl.logger.Errorw("error in deleting webhook subscription", "id", id, "err", err)
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, id, http.StatusOK)
+}
+
+func (impl *AdminRestHandlerImpl) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	deliveries, err := impl.webhookSubscriptionService.GetDeliveries(mux.Vars(r)["id"])
+	if err != nil {
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, deliveries, http.StatusOK)
+}
+
+func (impl *AdminRestHandlerImpl) GetGitHubWebhookStatus(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	writeJsonResp(w, nil, impl.webhookRegistrationService.GetStatus(), http.StatusOK)
+}
+
+// SyncGitHubWebhooks checks and repairs the github webhooks right away, e.g. after fixing token permissions
+func (impl *AdminRestHandlerImpl) SyncGitHubWebhooks(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	writeJsonResp(w, nil, impl.webhookRegistrationService.Sync(), http.StatusOK)
+}
+
+func (impl *AdminRestHandlerImpl) GetAnnouncements(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	writeJsonResp(w, nil, impl.announcementService.GetAnnouncements(), http.StatusOK)
+}
+
+func (impl *AdminRestHandlerImpl) GetAnnouncement(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	announcement, err := impl.announcementService.GetAnnouncement(mux.Vars(r)["id"])
+	if err != nil {
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, announcement, http.StatusOK)
+}
+
+func (impl *AdminRestHandlerImpl) CreateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	request := &common.AnnouncementRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		writeJsonResp(w, err, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	announcement, err := impl.announcementService.CreateAnnouncement(request)
+	if err != nil {
+		impl.logger.Errorw("error in creating announcement", "title", request.Title, "err", err)
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, announcement, http.StatusCreated)
+}
+
+func (impl *AdminRestHandlerImpl) UpdateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	request := &common.AnnouncementRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		writeJsonResp(w, err, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	id := mux.Vars(r)["id"]
+	announcement, err := impl.announcementService.UpdateAnnouncement(id, request)
+	if err != nil {
+		impl.logger.Errorw("error in updating announcement", "id", id, "err", err)
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, announcement, http.StatusOK)
+}
+
+func (impl *AdminRestHandlerImpl) DeleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	id := mux.Vars(r)["id"]
+	err := impl.announcementService.DeleteAnnouncement(id)
+	if err != nil {
+		impl.logger.Errorw("error in deleting announcement", "id", id, "err", err)
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, id, http.StatusOK)
+}
+
+// GetVersionAdoption counts the installations which sent a heartbeat within the last days by version
+func (impl *AdminRestHandlerImpl) GetVersionAdoption(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	days, err := getTelemetryWindowInDays(r)
+	if err != nil {
+		writeJsonResp(w, err, "invalid days", http.StatusBadRequest)
+		return
+	}
+	versionAdoption, err := impl.telemetryService.GetVersionAdoption(days)
+	if err != nil {
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, versionAdoption, http.StatusOK)
+}
+
+// GetModulePopularity counts the installations which sent a heartbeat within the last days by installed module
+func (impl *AdminRestHandlerImpl) GetModulePopularity(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	days, err := getTelemetryWindowInDays(r)
+	if err != nil {
+		writeJsonResp(w, err, "invalid days", http.StatusBadRequest)
+		return
+	}
+	modulePopularity, err := impl.telemetryService.GetModulePopularity(days)
+	if err != nil {
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, modulePopularity, http.StatusOK)
+}
+
+func (impl *AdminRestHandlerImpl) GetRolloutPolicies(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	writeJsonResp(w, nil, impl.rolloutService.GetPolicies(), http.StatusOK)
+}
+
+func (impl *AdminRestHandlerImpl) GetRolloutPolicy(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	vars := mux.Vars(r)
+	policy, err := impl.rolloutService.GetPolicy(bean.Repository(vars["repo"]), vars["tag"])
+	if err != nil {
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, policy, http.StatusOK)
+}
+
+// SaveRolloutPolicy creates or replaces the rollout policy of a release
+func (impl *AdminRestHandlerImpl) SaveRolloutPolicy(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	request := &common.RolloutPolicyRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		writeJsonResp(w, err, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	vars := mux.Vars(r)
+	policy, err := impl.rolloutService.SavePolicy(bean.Repository(vars["repo"]), vars["tag"], request)
+	if err != nil {
+		impl.logger.Errorw("error in saving rollout policy", "repo", vars["repo"], "tag", vars["tag"], "err", err)
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, policy, http.StatusOK)
+}
+
+// DeleteRolloutPolicy removes the rollout policy, the release is announced to all installations from then on
+func (impl *AdminRestHandlerImpl) DeleteRolloutPolicy(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	vars := mux.Vars(r)
+	err := impl.rolloutService.DeletePolicy(bean.Repository(vars["repo"]), vars["tag"])
+	if err != nil {
+		impl.logger.Errorw("error in deleting rollout policy", "repo", vars["repo"], "tag", vars["tag"], "err", err)
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, vars["tag"], http.StatusOK)
+}
+
+func (impl *AdminRestHandlerImpl) AdvanceRollout(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	vars := mux.Vars(r)
+	policy, err := impl.rolloutService.AdvancePolicy(bean.Repository(vars["repo"]), vars["tag"])
+	if err != nil {
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, policy, http.StatusOK)
+}
+
+// HaltRollout hides the release from all installations, e.g. when a regression is reported
+func (impl *AdminRestHandlerImpl) HaltRollout(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	if !impl.isAuthorized(w, r) {
+		return
+	}
+	vars := mux.Vars(r)
+	policy, err := impl.rolloutService.HaltPolicy(bean.Repository(vars["repo"]), vars["tag"])
+	if err != nil {
+		writeJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeJsonResp(w, nil, policy, http.StatusOK)
+}
+
+// getTelemetryWindowInDays reads the days query param, 0 when it is not set
+func getTelemetryWindowInDays(r *http.Request) (int, error) {
+	daysQueryParam := r.URL.Query().Get("days")
+	if len(daysQueryParam) == 0 {
+		return 0, nil
+	}
+	return strconv.Atoi(daysQueryParam)
+}
@@ -17,62 +17,142 @@
 package api
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"github.com/Masterminds/semver"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
 	util "github.com/devtron-labs/central-api/client"
 	"github.com/devtron-labs/central-api/common"
+	internalUtil "github.com/devtron-labs/central-api/internal/util"
 	"github.com/devtron-labs/central-api/pkg"
 	"github.com/devtron-labs/central-api/pkg/bean"
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
+const NonSemverTagsHeader = "X-Non-Semver-Tags"
+
+// InstallationIdHeader identifies the installation calling, the installationId query param takes precedence
+const InstallationIdHeader = "X-Devtron-Installation-Id"
+
 type RestHandler interface {
 	GetReleases(w http.ResponseWriter, r *http.Request)
+	GetLatestRelease(w http.ResponseWriter, r *http.Request)
+	GetReleaseByTag(w http.ResponseWriter, r *http.Request)
+	GetReleaseTimeline(w http.ResponseWriter, r *http.Request)
+	SearchReleases(w http.ResponseWriter, r *http.Request)
+	GetReleaseImages(w http.ResponseWriter, r *http.Request)
+	CompareReleases(w http.ResponseWriter, r *http.Request)
+	GetAtomFeed(w http.ResponseWriter, r *http.Request)
+	GetRssFeed(w http.ResponseWriter, r *http.Request)
+	GetUpgradePath(w http.ResponseWriter, r *http.Request)
+	ValidatePrerequisites(w http.ResponseWriter, r *http.Request)
+	GetChangelog(w http.ResponseWriter, r *http.Request)
+	GetBreakingChanges(w http.ResponseWriter, r *http.Request)
+	GetCompatibilityMatrix(w http.ResponseWriter, r *http.Request)
+	CheckUpgrade(w http.ResponseWriter, r *http.Request)
+	GetSecurityAdvisories(w http.ResponseWriter, r *http.Request)
+	GetActiveAnnouncements(w http.ResponseWriter, r *http.Request)
+	GetFeatureFlags(w http.ResponseWriter, r *http.Request)
+	SaveHeartbeat(w http.ResponseWriter, r *http.Request)
+	ValidateReleaseMetadata(w http.ResponseWriter, r *http.Request)
 	ReleaseWebhookHandler(w http.ResponseWriter, r *http.Request)
 	GetModules(w http.ResponseWriter, r *http.Request)
 	GetModulesV2(w http.ResponseWriter, r *http.Request)
 	GetModuleByName(w http.ResponseWriter, r *http.Request)
 	GetDockerfileTemplateMetadata(w http.ResponseWriter, r *http.Request)
 	GetBuildpackMetadata(w http.ResponseWriter, r *http.Request)
+	StreamEvents(w http.ResponseWriter, r *http.Request)
 }
 
 func NewRestHandlerImpl(logger *zap.SugaredLogger, releaseNoteService pkg.ReleaseNoteService,
-	webhookSecretValidator pkg.WebhookSecretValidator, client *util.GitHubClient, ciBuildMetadataService pkg.CiBuildMetadataService) *RestHandlerImpl {
+	webhookSecretValidator pkg.WebhookSecretValidator, client *util.GitHubClient, ciBuildMetadataService pkg.CiBuildMetadataService,
+	releaseChannelService pkg.ReleaseChannelService, releaseNoteRenderer pkg.ReleaseNoteRenderer,
+	releaseSearchService pkg.ReleaseSearchService, releaseFeedService pkg.ReleaseFeedService,
+	releaseManifestService pkg.ReleaseManifestService, releaseCompareService pkg.ReleaseCompareService,
+	eventStreamService pkg.EventStreamService, eventStreamConfig *util.EventStreamConfig,
+	upgradeCheckService pkg.UpgradeCheckService, securityAdvisoryService pkg.SecurityAdvisoryService,
+	announcementService pkg.AnnouncementService, featureFlagService pkg.FeatureFlagService,
+	telemetryService pkg.TelemetryService, telemetryConfig *util.TelemetryConfig, rolloutService pkg.RolloutService) *RestHandlerImpl {
 	return &RestHandlerImpl{
-		logger:                 logger,
-		releaseNoteService:     releaseNoteService,
-		webhookSecretValidator: webhookSecretValidator,
-		client:                 client,
-		ciBuildMetadataService: ciBuildMetadataService,
+		logger:                  logger,
+		releaseNoteService:      releaseNoteService,
+		webhookSecretValidator:  webhookSecretValidator,
+		client:                  client,
+		ciBuildMetadataService:  ciBuildMetadataService,
+		releaseChannelService:   releaseChannelService,
+		releaseNoteRenderer:     releaseNoteRenderer,
+		releaseSearchService:    releaseSearchService,
+		releaseFeedService:      releaseFeedService,
+		releaseManifestService:  releaseManifestService,
+		releaseCompareService:   releaseCompareService,
+		eventStreamService:      eventStreamService,
+		eventStreamConfig:       eventStreamConfig,
+		upgradeCheckService:     upgradeCheckService,
+		securityAdvisoryService: securityAdvisoryService,
+		announcementService:     announcementService,
+		featureFlagService:      featureFlagService,
+		telemetryService:        telemetryService,
+		telemetryConfig:         telemetryConfig,
+		rolloutService:          rolloutService,
 	}
 }
 
 type RestHandlerImpl struct {
-	logger                 *zap.SugaredLogger
-	releaseNoteService     pkg.ReleaseNoteService
-	webhookSecretValidator pkg.WebhookSecretValidator
-	client                 *util.GitHubClient
-	ciBuildMetadataService pkg.CiBuildMetadataService
+	logger                  *zap.SugaredLogger
+	releaseNoteService      pkg.ReleaseNoteService
+	webhookSecretValidator  pkg.WebhookSecretValidator
+	client                  *util.GitHubClient
+	ciBuildMetadataService  pkg.CiBuildMetadataService
+	releaseChannelService   pkg.ReleaseChannelService
+	releaseNoteRenderer     pkg.ReleaseNoteRenderer
+	releaseSearchService    pkg.ReleaseSearchService
+	releaseFeedService      pkg.ReleaseFeedService
+	releaseManifestService  pkg.ReleaseManifestService
+	releaseCompareService   pkg.ReleaseCompareService
+	eventStreamService      pkg.EventStreamService
+	eventStreamConfig       *util.EventStreamConfig
+	upgradeCheckService     pkg.UpgradeCheckService
+	securityAdvisoryService pkg.SecurityAdvisoryService
+	announcementService     pkg.AnnouncementService
+	featureFlagService      pkg.FeatureFlagService
+	telemetryService        pkg.TelemetryService
+	telemetryConfig         *util.TelemetryConfig
+	rolloutService          pkg.RolloutService
 }
 
 func setupResponse(w *http.ResponseWriter, req *http.Request) {
 	(*w).Header().Set("Access-Control-Allow-Origin", "*")
 	(*w).Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
-	(*w).Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+	(*w).Header().Set("Access-Control-Allow-Headers", "Accept, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, "+InstallationIdHeader)
 	(*w).Header().Set("Content-Type", "text/html; charset=utf-8")
 }
 
+// WriteJsonResp writes the response of all handlers, errors of services carrying their own status and message as
+// internalUtil.ApiError are written with these
 func (impl RestHandlerImpl) WriteJsonResp(w http.ResponseWriter, err error, respBody interface{}, status int) {
 	response := common.Response{}
-	response.Code = status
-	response.Status = http.StatusText(status)
 	if err == nil {
 		response.Result = respBody
+	} else if serviceErr, ok := err.(*internalUtil.ApiError); ok {
+		if serviceErr.HttpStatusCode != 0 {
+			status = serviceErr.HttpStatusCode
+		}
+		apiErr := &common.ApiError{
+			Code:              serviceErr.Code,
+			InternalMessage:   serviceErr.InternalMessage,
+			UserMessage:       serviceErr.UserMessage,
+			UserDetailMessage: serviceErr.UserDetailMessage,
+		}
+		response.Errors = []*common.ApiError{apiErr}
 	} else {
 		apiErr := &common.ApiError{}
 		apiErr.Code = "000" // 000=unknown
@@ -81,6 +161,8 @@ func (impl RestHandlerImpl) WriteJsonResp(w http.ResponseWriter, err error, resp
 		response.Errors = []*common.ApiError{apiErr}
 
 	}
+	response.Code = status
+	response.Status = http.StatusText(status)
 	b, err := json.Marshal(response)
 	if err != nil {
 		impl.logger.Errorw("error in marshaling err object", "err", err)
@@ -91,6 +173,52 @@ func (impl RestHandlerImpl) WriteJsonResp(w http.ResponseWriter, err error, resp
 	w.Write(b)
 }
 
+// writeNotFoundResp writes a structured 404 error with the given message
+func (impl RestHandlerImpl) writeNotFoundResp(w http.ResponseWriter, message string) {
+	impl.WriteJsonResp(w, internalUtil.NewNotFoundError(message), nil, http.StatusNotFound)
+}
+
+// WriteConditionalJsonResp writes a successful response with an ETag of its content and answers
+// 304 Not Modified when the ETag matches If-None-Match of the request
+func (impl RestHandlerImpl) WriteConditionalJsonResp(w http.ResponseWriter, r *http.Request, respBody interface{}) {
+	response := common.Response{}
+	response.Code = http.StatusOK
+	response.Status = http.StatusText(http.StatusOK)
+	response.Result = respBody
+	b, err := json.Marshal(response)
+	if err != nil {
+		impl.logger.Errorw("error in marshaling response", "err", err)
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeConditionalResp(w, r, "application/json", b)
+}
+
+// writeConditionalResp writes body with an ETag of its content, or 304 Not Modified when it matches If-None-Match
+func writeConditionalResp(w http.ResponseWriter, r *http.Request, contentType string, body []byte) {
+	hash := sha256.Sum256(body)
+	etag := fmt.Sprintf(`"%s"`, hex.EncodeToString(hash[:16]))
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache")
+	if isETagMatched(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+func isETagMatched(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
 func (impl *RestHandlerImpl) GetModules(w http.ResponseWriter, r *http.Request) {
 	impl.logger.Debug("get all modules")
 	setupResponse(&w, r)
@@ -118,68 +246,47 @@ func (impl *RestHandlerImpl) GetModulesV2(w http.ResponseWriter, r *http.Request
 func (impl *RestHandlerImpl) GetReleases(w http.ResponseWriter, r *http.Request) {
 	setupResponse(&w, r)
 	impl.logger.Debug("get all releases")
-	offset := 0
-	size := 10
-	var err error
-	offsetQueryParam := r.URL.Query().Get("offset")
-	sizeQueryParam := r.URL.Query().Get("size")
-	hasOffsetParam := len(offsetQueryParam) > 0
-	hasSizeParam := len(sizeQueryParam) > 0
-
-	if hasOffsetParam {
-		offset, err = strconv.Atoi(offsetQueryParam)
-		if err != nil {
-			impl.WriteJsonResp(w, err, "invalid offset", http.StatusBadRequest)
-			return
-		}
-	}
-	if hasSizeParam {
-		size, err = strconv.Atoi(sizeQueryParam)
-		if err != nil {
-			impl.WriteJsonResp(w, err, "invalid size", http.StatusBadRequest)
-			return
-		}
+	pagination, err := getReleasePagination(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, err.Error(), http.StatusBadRequest)
+		return
 	}
 	repo := r.URL.Query().Get("repo")
 	repository := bean.Oss
 	if len(repo) > 0 {
 		repository = bean.Repository(repo)
 	}
-	serverVersion := r.URL.Query().Get("serverVersion")
+	format, err := getReleaseNoteFormat(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, "invalid format", http.StatusBadRequest)
+		return
+	}
+	filter, err := getReleaseFilter(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(filter.Channel) > 0 && !impl.releaseChannelService.IsValidChannel(repository, filter.Channel) {
+		impl.WriteJsonResp(w, fmt.Errorf("unknown channel %s", filter.Channel), "invalid channel", http.StatusBadRequest)
+		return
+	}
 	//will fetch all the releases from cache and later apply size and offset filter
-	response, err := impl.releaseNoteService.GetReleases(repository)
+	response, err := impl.getVisibleReleases(repository, getInstallationId(r))
 	if err != nil {
 		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
 	}
-	if len(serverVersion) > 0 {
-		// get all releases of that version and above that version
-		var filteredResponse []*common.Release
-		for _, release := range response {
-			// Compare version strings - include matching version and newer versions
-			if release.TagName == serverVersion || isVersionNewer(release.TagName, serverVersion) {
-				filteredResponse = append(filteredResponse, release)
-			}
-		}
-		response = filteredResponse
-
-		// If serverVersion is provided, only apply pagination if size are explicitly provided
-		if hasSizeParam && size > 0 {
-			if offset+size <= len(response) {
-				response = response[offset : offset+size]
-			} else {
-				response = response[offset:]
-			}
-		}
-	} else {
-		// If serverVersion is not provided, apply pagination with default or provided values
-		if size > 0 {
-			if offset+size <= len(response) {
-				response = response[offset : offset+size]
-			} else {
-				response = response[offset:]
-			}
-		}
+	response, nonSemverTags := pkg.FilterReleases(response, filter)
+	if len(nonSemverTags) > 0 {
+		w.Header().Set(NonSemverTagsHeader, strings.Join(nonSemverTags, ","))
+	}
+	if pagination.isApplicable(filter) {
+		response = paginate(response, pagination.offset, pagination.size)
+	}
+	response, err = impl.renderReleases(response, format, repository)
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
 	}
 	if len(response) == 0 {
 		response = make([]*common.Release, 0)
@@ -189,94 +296,1128 @@ func (impl *RestHandlerImpl) GetReleases(w http.ResponseWriter, r *http.Request)
 	return
 }
 
-func (impl *RestHandlerImpl) ReleaseWebhookHandler(w http.ResponseWriter, r *http.Request) {
-	impl.logger.Debug("release webhook handler received event")
-	// get git host Id and secret from request
-	vars := mux.Vars(r)
-	secretFromRequest := vars["secret"]
-	impl.logger.Debugw("secret found in request", "secret", secretFromRequest)
+// GetReleaseTimeline merges the releases of several repositories, all configured ones by default, newest first
+// by publish time. Filters and pagination are the ones of GetReleases, version filters apply to every repository.
+func (impl *RestHandlerImpl) GetReleaseTimeline(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("get release timeline")
+	pagination, err := getReleasePagination(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, err.Error(), http.StatusBadRequest)
+		return
+	}
+	format, err := getReleaseNoteFormat(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, "invalid format", http.StatusBadRequest)
+		return
+	}
+	filter, err := getReleaseFilter(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var repositories []bean.Repository
+	if repos := r.URL.Query().Get("repos"); len(repos) > 0 {
+		for _, repo := range strings.Split(repos, ",") {
+			if repo = strings.TrimSpace(repo); len(repo) > 0 {
+				repositories = append(repositories, bean.Repository(repo))
+			}
+		}
+	} else {
+		for _, githubRepository := range impl.client.Repositories {
+			repositories = append(repositories, bean.Repository(githubRepository.Alias))
+		}
+	}
+	for _, repository := range repositories {
+		if !impl.client.IsConfiguredRepository(repository.String()) {
+			impl.WriteJsonResp(w, fmt.Errorf("unknown repo %s", repository), "invalid repos", http.StatusBadRequest)
+			return
+		}
+		if len(filter.Channel) > 0 && !impl.releaseChannelService.IsValidChannel(repository, filter.Channel) {
+			impl.WriteJsonResp(w, fmt.Errorf("unknown channel %s for repo %s", filter.Channel, repository), "invalid channel", http.StatusBadRequest)
+			return
+		}
+	}
+	timeline := make([]*common.TimelineRelease, 0)
+	var nonSemverTags []string
+	installationId := getInstallationId(r)
+	for _, repository := range repositories {
+		releases, err := impl.getVisibleReleases(repository, installationId)
+		if err != nil {
+			impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		releases, repositoryNonSemverTags := pkg.FilterReleases(releases, filter)
+		for _, tag := range repositoryNonSemverTags {
+			nonSemverTags = append(nonSemverTags, fmt.Sprintf("%s:%s", repository, tag))
+		}
+		for _, release := range releases {
+			timeline = append(timeline, &common.TimelineRelease{Repository: repository.String(), Release: release})
+		}
+	}
+	if len(nonSemverTags) > 0 {
+		w.Header().Set(NonSemverTagsHeader, strings.Join(nonSemverTags, ","))
+	}
+	// stable sort keeps the order of repos and of versions within a repo for releases published together
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return getReleaseTime(timeline[i].Release).After(getReleaseTime(timeline[j].Release))
+	})
+	if pagination.isApplicable(filter) {
+		timeline = paginate(timeline, pagination.offset, pagination.size)
+	}
+	for _, entry := range timeline {
+		renderedReleases, err := impl.renderReleases([]*common.Release{entry.Release}, format, bean.Repository(entry.Repository))
+		if err != nil {
+			impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		entry.Release = renderedReleases[0]
+	}
+	impl.WriteJsonResp(w, nil, timeline, http.StatusOK)
+}
 
-	// validate signature
-	requestBodyBytes, err := ioutil.ReadAll(r.Body)
+func (impl *RestHandlerImpl) GetLatestRelease(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("get latest release")
+	repo := r.URL.Query().Get("repo")
+	repository := bean.Oss
+	if len(repo) > 0 {
+		repository = bean.Repository(repo)
+	}
+	format, err := getReleaseNoteFormat(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, "invalid format", http.StatusBadRequest)
+		return
+	}
+	channel := r.URL.Query().Get("channel")
+	if len(channel) > 0 && !impl.releaseChannelService.IsValidChannel(repository, channel) {
+		impl.WriteJsonResp(w, fmt.Errorf("unknown channel %s", channel), "invalid channel", http.StatusBadRequest)
+		return
+	}
+	release, err := impl.getLatestVisibleRelease(repository, channel, getInstallationId(r))
 	if err != nil {
-		impl.logger.Errorw("Cannot read the request body:", "err", err)
 		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
 	}
-
-	isValidSig := impl.webhookSecretValidator.ValidateSecret(r, requestBodyBytes)
-	impl.logger.Debugw("Secret validation result ", "isValidSig", isValidSig)
-	if !isValidSig {
-		impl.logger.Error("Signature mismatch")
-		impl.WriteJsonResp(w, err, nil, http.StatusUnauthorized)
+	if release == nil {
+		impl.writeNotFoundResp(w, fmt.Sprintf("no release found for repo %s", repository))
 		return
 	}
-	// validate event type
-	eventType := r.Header.Get(impl.client.GitHubConfig.GitHubEventTypeHeader)
-	impl.logger.Debugw("webhook event type header", "eventType : ", eventType)
-	if len(eventType) == 0 || eventType != bean.EventTypeRelease {
-		impl.logger.Errorw("Event type not known ", eventType)
-		impl.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+	releases, err := impl.renderReleases([]*common.Release{release}, format, repository)
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
 	}
+	impl.WriteConditionalJsonResp(w, r, releases[0])
+	return
+}
 
-	flag, err := impl.releaseNoteService.UpdateReleases(requestBodyBytes)
+func (impl *RestHandlerImpl) GetReleaseByTag(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("get release by tag")
+	tag := mux.Vars(r)["tag"]
+	repo := r.URL.Query().Get("repo")
+	repository := bean.Oss
+	if len(repo) > 0 {
+		repository = bean.Repository(repo)
+	}
+	format, err := getReleaseNoteFormat(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, "invalid format", http.StatusBadRequest)
+		return
+	}
+	release, err := impl.getVisibleReleaseByTag(repository, tag, getInstallationId(r))
 	if err != nil {
 		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
 	}
-	impl.WriteJsonResp(w, err, flag, http.StatusOK)
+	if release == nil {
+		impl.writeNotFoundResp(w, fmt.Sprintf("release %s not found for repo %s", tag, repository))
+		return
+	}
+	releases, err := impl.renderReleases([]*common.Release{release}, format, repository)
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	impl.WriteConditionalJsonResp(w, r, releases[0])
 	return
 }
 
-func (impl *RestHandlerImpl) GetModuleByName(w http.ResponseWriter, r *http.Request) {
-	impl.logger.Debug("get module meta info by name")
+// GetReleaseImages returns the component manifest of a release, format=text lists one image per line for scripting
+func (impl *RestHandlerImpl) GetReleaseImages(w http.ResponseWriter, r *http.Request) {
 	setupResponse(&w, r)
-	vars := mux.Vars(r)
-	name := vars["name"]
-	module, err := impl.releaseNoteService.GetModuleByName(name)
+	impl.logger.Debug("get release images")
+	tag := mux.Vars(r)["tag"]
+	repo := r.URL.Query().Get("repo")
+	repository := bean.Oss
+	if len(repo) > 0 {
+		repository = bean.Repository(repo)
+	}
+	format := r.URL.Query().Get("format")
+	if len(format) > 0 && format != "json" && format != "text" {
+		impl.WriteJsonResp(w, fmt.Errorf("unsupported format %s", format), "invalid format", http.StatusBadRequest)
+		return
+	}
+	release, err := impl.getVisibleReleaseByTag(repository, tag, getInstallationId(r))
 	if err != nil {
 		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
 		return
 	}
-	impl.WriteJsonResp(w, nil, module, http.StatusOK)
+	if release == nil {
+		impl.writeNotFoundResp(w, fmt.Sprintf("release %s not found for repo %s", tag, repository))
+		return
+	}
+	manifest, err := impl.releaseManifestService.GetComponentManifest(repository, release)
+	if err != nil {
+		impl.WriteJsonResp(w, err, "error in reading component manifest", http.StatusBadGateway)
+		return
+	}
+	if manifest == nil {
+		impl.writeNotFoundResp(w, fmt.Sprintf("no component manifest attached to release %s", tag))
+		return
+	}
+	if format == "text" {
+		var images strings.Builder
+		for _, image := range manifest.Images {
+			images.WriteString(image.Image)
+			images.WriteString("\n")
+		}
+		writeConditionalResp(w, r, "text/plain; charset=utf-8", []byte(images.String()))
+		return
+	}
+	impl.WriteConditionalJsonResp(w, r, manifest)
 	return
 }
 
-func (impl *RestHandlerImpl) GetDockerfileTemplateMetadata(w http.ResponseWriter, r *http.Request) {
-	impl.logger.Debug("get all dockerfile template metadata")
+func (impl *RestHandlerImpl) CompareReleases(w http.ResponseWriter, r *http.Request) {
 	setupResponse(&w, r)
-	dockerfileTemplateMetadata := impl.ciBuildMetadataService.GetDockerfileTemplateMetadata()
-	impl.WriteJsonResp(w, nil, dockerfileTemplateMetadata, http.StatusOK)
+	impl.logger.Debug("compare releases")
+	base := r.URL.Query().Get("base")
+	head := r.URL.Query().Get("head")
+	if len(base) == 0 || len(head) == 0 {
+		impl.WriteJsonResp(w, fmt.Errorf("base and head are required"), "invalid base or head", http.StatusBadRequest)
+		return
+	}
+	repo := r.URL.Query().Get("repo")
+	repository := bean.Oss
+	if len(repo) > 0 {
+		repository = bean.Repository(repo)
+	}
+	// only release tags are compared, they are immutable which keeps cached comparisons valid
+	releases := make([]*common.Release, 0, 2)
+	for _, tag := range []string{base, head} {
+		release, err := impl.getVisibleReleaseByTag(repository, tag, getInstallationId(r))
+		if err != nil {
+			impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+			return
+		}
+		if release == nil {
+			impl.writeNotFoundResp(w, fmt.Sprintf("release %s not found for repo %s", tag, repository))
+			return
+		}
+		releases = append(releases, release)
+	}
+	// tags are resolved with or without the v prefix, github only knows the actual tag names
+	comparison, err := impl.releaseCompareService.CompareReleases(repository, releases[0].TagName, releases[1].TagName)
+	if err != nil {
+		impl.WriteJsonResp(w, err, "error in comparing releases on github", http.StatusBadGateway)
+		return
+	}
+	impl.WriteConditionalJsonResp(w, r, comparison)
 	return
 }
-func (impl *RestHandlerImpl) GetBuildpackMetadata(w http.ResponseWriter, r *http.Request) {
-	impl.logger.Debug("get all buildpack metadata")
+
+func (impl *RestHandlerImpl) SearchReleases(w http.ResponseWriter, r *http.Request) {
 	setupResponse(&w, r)
-	buildpackMetadata := impl.ciBuildMetadataService.GetBuildpackMetadata()
-	impl.WriteJsonResp(w, nil, buildpackMetadata, http.StatusOK)
+	impl.logger.Debug("search releases")
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if len(query) == 0 {
+		impl.WriteJsonResp(w, fmt.Errorf("query is required"), "invalid q", http.StatusBadRequest)
+		return
+	}
+	size := 20
+	var err error
+	sizeQueryParam := r.URL.Query().Get("size")
+	if len(sizeQueryParam) > 0 {
+		size, err = strconv.Atoi(sizeQueryParam)
+		if err != nil || size <= 0 || size > 100 {
+			impl.WriteJsonResp(w, fmt.Errorf("size should be between 1 and 100"), "invalid size", http.StatusBadRequest)
+			return
+		}
+	}
+	repo := r.URL.Query().Get("repo")
+	repository := bean.Oss
+	if len(repo) > 0 {
+		repository = bean.Repository(repo)
+	}
+	var versionConstraint *semver.Constraints
+	versionRange := r.URL.Query().Get("versionRange")
+	if len(versionRange) > 0 {
+		versionConstraint, err = semver.NewConstraint(versionRange)
+		if err != nil {
+			impl.WriteJsonResp(w, err, "invalid versionRange", http.StatusBadRequest)
+			return
+		}
+	}
+	includePrerelease := false
+	includePrereleaseQueryParam := r.URL.Query().Get("includePrerelease")
+	if len(includePrereleaseQueryParam) > 0 {
+		includePrerelease, err = strconv.ParseBool(includePrereleaseQueryParam)
+		if err != nil {
+			impl.WriteJsonResp(w, err, "invalid includePrerelease", http.StatusBadRequest)
+			return
+		}
+	}
+	results, err := impl.releaseSearchService.Search(repository, query, versionConstraint, includePrerelease, size, getInstallationId(r))
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	impl.WriteJsonResp(w, nil, results, http.StatusOK)
 	return
 }
 
-// isVersionNewer compares two version strings and returns true if v1 is newer than v2
-func isVersionNewer(v1, v2 string) bool {
-	// Ensure 'v' prefix is present for semver parsing
-	if !strings.HasPrefix(v1, "v") {
-		v1 = "v" + v1
+func (impl *RestHandlerImpl) GetAtomFeed(w http.ResponseWriter, r *http.Request) {
+	impl.writeReleaseFeed(w, r, bean.ReleaseFeedFormatAtom, "application/atom+xml; charset=utf-8")
+}
+
+func (impl *RestHandlerImpl) GetRssFeed(w http.ResponseWriter, r *http.Request) {
+	impl.writeReleaseFeed(w, r, bean.ReleaseFeedFormatRss, "application/rss+xml; charset=utf-8")
+}
+
+func (impl *RestHandlerImpl) writeReleaseFeed(w http.ResponseWriter, r *http.Request, format bean.ReleaseFeedFormat, contentType string) {
+	setupResponse(&w, r)
+	impl.logger.Debugw("get release feed", "format", format)
+	repo := r.URL.Query().Get("repo")
+	repository := bean.Oss
+	if len(repo) > 0 {
+		repository = bean.Repository(repo)
 	}
-	if !strings.HasPrefix(v2, "v") {
-		v2 = "v" + v2
+	channel := r.URL.Query().Get("channel")
+	if len(channel) > 0 && !impl.releaseChannelService.IsValidChannel(repository, channel) {
+		impl.WriteJsonResp(w, fmt.Errorf("unknown channel %s", channel), "invalid channel", http.StatusBadRequest)
+		return
 	}
+	feed, err := impl.releaseFeedService.GetFeed(repository, channel, format, getFeedSelfUrl(r, repo, channel), getInstallationId(r))
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	writeConditionalResp(w, r, contentType, feed)
+}
 
-	// Parse versions
-	ver1, err1 := semver.NewVersion(v1)
-	ver2, err2 := semver.NewVersion(v2)
-
-	// Fall back to string comparison if parsing fails
-	if err1 != nil || err2 != nil {
-		return strings.TrimPrefix(v1, "v") > strings.TrimPrefix(v2, "v")
+// getFeedSelfUrl returns the absolute url of the feed request, only repo and channel are kept from the query
+func getFeedSelfUrl(r *http.Request, repo string, channel string) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	if forwardedProto := r.Header.Get("X-Forwarded-Proto"); forwardedProto == "http" || forwardedProto == "https" {
+		scheme = forwardedProto
+	}
+	query := url.Values{}
+	if len(repo) > 0 {
+		query.Set("repo", repo)
+	}
+	if len(channel) > 0 {
+		query.Set("channel", channel)
 	}
+	selfUrl := url.URL{Scheme: scheme, Host: r.Host, Path: r.URL.Path, RawQuery: query.Encode()}
+	return selfUrl.String()
+}
 
-	// Compare using semver
-	return ver1.GreaterThan(ver2)
+func (impl *RestHandlerImpl) GetUpgradePath(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("get upgrade path")
+	from, to, userMessage, err := getFromAndToVersion(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, userMessage, http.StatusBadRequest)
+		return
+	}
+	includePrerelease := false
+	includePrereleaseQueryParam := r.URL.Query().Get("includePrerelease")
+	if len(includePrereleaseQueryParam) > 0 {
+		includePrerelease, err = strconv.ParseBool(includePrereleaseQueryParam)
+		if err != nil {
+			impl.WriteJsonResp(w, err, "invalid includePrerelease", http.StatusBadRequest)
+			return
+		}
+	}
+	repo := r.URL.Query().Get("repo")
+	repository := bean.Oss
+	if len(repo) > 0 {
+		repository = bean.Repository(repo)
+	}
+	format, err := getReleaseNoteFormat(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, "invalid format", http.StatusBadRequest)
+		return
+	}
+	releases, err := impl.getVisibleReleases(repository, getInstallationId(r))
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	pathReleases := getReleasesInUpgradeOrder(pkg.FilterPublishedReleases(releases, includePrerelease), from, to)
+	pathReleases, err = impl.renderReleases(pathReleases, format, repository)
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	upgradePath := &common.UpgradePath{
+		From:              from,
+		To:                to,
+		Releases:          make([]*common.Release, 0),
+		Prerequisites:     make([]*common.UpgradePrerequisite, 0),
+		MandatoryReleases: make([]string, 0),
+	}
+	for _, release := range pathReleases {
+		upgradePath.Releases = append(upgradePath.Releases, release)
+		if release.Prerequisite {
+			upgradePath.Prerequisites = append(upgradePath.Prerequisites, &common.UpgradePrerequisite{
+				TagName: release.TagName,
+				Message: release.PrerequisiteMessage,
+				Items:   release.Prerequisites,
+			})
+		}
+		if release.MandatoryUpgrade {
+			upgradePath.MandatoryReleases = append(upgradePath.MandatoryReleases, release.TagName)
+		}
+	}
+	if len(to) == 0 && len(upgradePath.Releases) > 0 {
+		upgradePath.To = upgradePath.Releases[len(upgradePath.Releases)-1].TagName
+	}
+	impl.WriteJsonResp(w, nil, upgradePath, http.StatusOK)
+	return
+}
+
+func (impl *RestHandlerImpl) GetChangelog(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("get changelog")
+	from, to, userMessage, err := getFromAndToVersion(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, userMessage, http.StatusBadRequest)
+		return
+	}
+	repo := r.URL.Query().Get("repo")
+	repository := bean.Oss
+	if len(repo) > 0 {
+		repository = bean.Repository(repo)
+	}
+	releases, err := impl.getVisibleReleases(repository, getInstallationId(r))
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	rangeReleases := getReleasesInUpgradeOrder(pkg.FilterPublishedReleases(releases, false), from, to)
+	// newest changes are listed first
+	for i, j := 0, len(rangeReleases)-1; i < j; i, j = i+1, j-1 {
+		rangeReleases[i], rangeReleases[j] = rangeReleases[j], rangeReleases[i]
+	}
+	changelog := &common.Changelog{
+		From:     from,
+		To:       to,
+		Releases: make([]string, 0, len(rangeReleases)),
+		Sections: impl.releaseNoteService.MergeReleaseSections(rangeReleases),
+	}
+	for _, release := range rangeReleases {
+		changelog.Releases = append(changelog.Releases, release.TagName)
+	}
+	if len(to) == 0 && len(rangeReleases) > 0 {
+		changelog.To = rangeReleases[0].TagName
+	}
+	impl.WriteJsonResp(w, nil, changelog, http.StatusOK)
+	return
+}
+
+// GetBreakingChanges lists the breaking changes and deprecations of the releases after from up to to, newest first
+func (impl *RestHandlerImpl) GetBreakingChanges(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("get breaking changes")
+	from, to, userMessage, err := getFromAndToVersion(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, userMessage, http.StatusBadRequest)
+		return
+	}
+	repo := r.URL.Query().Get("repo")
+	repository := bean.Oss
+	if len(repo) > 0 {
+		repository = bean.Repository(repo)
+	}
+	releases, err := impl.getVisibleReleases(repository, getInstallationId(r))
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	rangeReleases := getReleasesInUpgradeOrder(pkg.FilterPublishedReleases(releases, false), from, to)
+	for i, j := 0, len(rangeReleases)-1; i < j; i, j = i+1, j-1 {
+		rangeReleases[i], rangeReleases[j] = rangeReleases[j], rangeReleases[i]
+	}
+	breakingChanges := impl.releaseNoteService.MergeReleaseNotices(rangeReleases)
+	breakingChanges.From = from
+	breakingChanges.To = to
+	if len(to) == 0 && len(rangeReleases) > 0 {
+		breakingChanges.To = rangeReleases[0].TagName
+	}
+	impl.WriteJsonResp(w, nil, breakingChanges, http.StatusOK)
+	return
+}
+
+func (impl *RestHandlerImpl) ValidatePrerequisites(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("validate release prerequisites")
+	request := &common.PrerequisiteValidationRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		impl.WriteJsonResp(w, err, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	result := impl.releaseNoteService.ValidatePrerequisites(request.Body)
+	impl.WriteJsonResp(w, nil, result, http.StatusOK)
+	return
+}
+
+func (impl *RestHandlerImpl) ValidateReleaseMetadata(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("validate release metadata")
+	request := &common.ReleaseMetadataValidationRequest{}
+	err := json.NewDecoder(r.Body).Decode(request)
+	if err != nil {
+		impl.WriteJsonResp(w, err, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	result := impl.releaseNoteService.ValidateReleaseMetadata(request.Body)
+	impl.WriteJsonResp(w, nil, result, http.StatusOK)
+	return
+}
+
+// GetCompatibilityMatrix lists the compatibility data of published releases declaring it, newest first.
+// With k8sVersion only the releases supporting that kubernetes version are listed.
+func (impl *RestHandlerImpl) GetCompatibilityMatrix(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("get compatibility matrix")
+	repo := r.URL.Query().Get("repo")
+	repository := bean.Oss
+	if len(repo) > 0 {
+		repository = bean.Repository(repo)
+	}
+	k8sVersion := r.URL.Query().Get("k8sVersion")
+	var kubernetesVersion *semver.Version
+	if len(k8sVersion) > 0 {
+		var err error
+		kubernetesVersion, err = semver.NewVersion(k8sVersion)
+		if err != nil {
+			impl.WriteJsonResp(w, err, "invalid k8sVersion", http.StatusBadRequest)
+			return
+		}
+	}
+	filter, err := getReleaseFilter(r)
+	if err != nil {
+		impl.WriteJsonResp(w, err, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(filter.Channel) > 0 && !impl.releaseChannelService.IsValidChannel(repository, filter.Channel) {
+		impl.WriteJsonResp(w, fmt.Errorf("unknown channel %s", filter.Channel), "invalid channel", http.StatusBadRequest)
+		return
+	}
+	releases, err := impl.getVisibleReleases(repository, getInstallationId(r))
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	releases, _ = pkg.FilterReleases(releases, filter)
+	matrix := &common.CompatibilityMatrix{
+		KubernetesVersion: k8sVersion,
+		Releases:          make([]*common.CompatibilityEntry, 0),
+	}
+	now := time.Now()
+	for _, release := range releases {
+		if release.Compatibility == nil {
+			continue
+		}
+		if kubernetesVersion != nil && !pkg.IsKubernetesVersionSupported(release.Compatibility, kubernetesVersion) {
+			continue
+		}
+		matrix.Releases = append(matrix.Releases, &common.CompatibilityEntry{
+			TagName:              release.TagName,
+			ReleaseCompatibility: release.Compatibility,
+			EndOfLifeReached:     pkg.IsEndOfLifeReached(release.Compatibility, now),
+		})
+	}
+	impl.WriteJsonResp(w, nil, matrix, http.StatusOK)
+	return
+}
+
+// CheckUpgrade tells an installation running currentVersion whether a newer release of its channel is out.
+// modules are the installed modules as name:version pairs separated by commas, the version is optional.
+func (impl *RestHandlerImpl) CheckUpgrade(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("check upgrade")
+	currentVersion := r.URL.Query().Get("currentVersion")
+	if len(currentVersion) == 0 {
+		impl.WriteJsonResp(w, fmt.Errorf("currentVersion is required"), "currentVersion is required", http.StatusBadRequest)
+		return
+	}
+	repo := r.URL.Query().Get("repo")
+	repository := bean.Oss
+	if len(repo) > 0 {
+		repository = bean.Repository(repo)
+	}
+	includePrerelease := false
+	includePrereleaseQueryParam := r.URL.Query().Get("includePrerelease")
+	if len(includePrereleaseQueryParam) > 0 {
+		var err error
+		includePrerelease, err = strconv.ParseBool(includePrereleaseQueryParam)
+		if err != nil {
+			impl.WriteJsonResp(w, err, "invalid includePrerelease", http.StatusBadRequest)
+			return
+		}
+	}
+	modules, err := getInstalledModules(r.URL.Query().Get("modules"))
+	if err != nil {
+		impl.WriteJsonResp(w, err, err.Error(), http.StatusBadRequest)
+		return
+	}
+	upgradeCheck, err := impl.upgradeCheckService.CheckUpgrade(repository, &common.UpgradeCheckRequest{
+		InstallationId:    getInstallationId(r),
+		CurrentVersion:    currentVersion,
+		Channel:           r.URL.Query().Get("channel"),
+		IncludePrerelease: includePrerelease,
+		Modules:           modules,
+	})
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	impl.WriteJsonResp(w, nil, upgradeCheck, http.StatusOK)
+	return
+}
+
+// GetSecurityAdvisories lists the security advisories of a repository and the release fixing each one,
+// with version only the advisories affecting that version are listed
+func (impl *RestHandlerImpl) GetSecurityAdvisories(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("get security advisories")
+	repo := r.URL.Query().Get("repo")
+	repository := bean.Oss
+	if len(repo) > 0 {
+		repository = bean.Repository(repo)
+	}
+	advisories, err := impl.securityAdvisoryService.GetAdvisories(repository, r.URL.Query().Get("version"))
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	impl.WriteJsonResp(w, nil, advisories, http.StatusOK)
+	return
+}
+
+// GetActiveAnnouncements lists the announcements to show to an installation of the given version and edition,
+// modules are passed like on /release/check
+func (impl *RestHandlerImpl) GetActiveAnnouncements(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("get active announcements")
+	modules, err := getInstalledModules(r.URL.Query().Get("modules"))
+	if err != nil {
+		impl.WriteJsonResp(w, err, err.Error(), http.StatusBadRequest)
+		return
+	}
+	installation := &common.InstallationContext{
+		Version: r.URL.Query().Get("version"),
+		Edition: r.URL.Query().Get("edition"),
+	}
+	for module := range modules {
+		installation.Modules = append(installation.Modules, module)
+	}
+	announcements, err := impl.announcementService.GetActiveAnnouncements(installation)
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	impl.WriteJsonResp(w, nil, announcements, http.StatusOK)
+	return
+}
+
+// GetFeatureFlags evaluates the feature flags for an installation, keys restricts the flags evaluated and
+// explain=true adds how each value was chosen
+func (impl *RestHandlerImpl) GetFeatureFlags(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	impl.logger.Debug("get feature flags")
+	modules, err := getInstalledModules(r.URL.Query().Get("modules"))
+	if err != nil {
+		impl.WriteJsonResp(w, err, err.Error(), http.StatusBadRequest)
+		return
+	}
+	explain := false
+	explainQueryParam := r.URL.Query().Get("explain")
+	if len(explainQueryParam) > 0 {
+		explain, err = strconv.ParseBool(explainQueryParam)
+		if err != nil {
+			impl.WriteJsonResp(w, err, "invalid explain", http.StatusBadRequest)
+			return
+		}
+	}
+	var keys []string
+	if keysQueryParam := r.URL.Query().Get("keys"); len(keysQueryParam) > 0 {
+		for _, key := range strings.Split(keysQueryParam, ",") {
+			if key = strings.TrimSpace(key); len(key) > 0 {
+				keys = append(keys, key)
+			}
+		}
+	}
+	installation := &common.InstallationContext{
+		InstallationId: getInstallationId(r),
+		Version:        r.URL.Query().Get("version"),
+		Edition:        r.URL.Query().Get("edition"),
+	}
+	for module := range modules {
+		installation.Modules = append(installation.Modules, module)
+	}
+	evaluation, err := impl.featureFlagService.EvaluateFlags(installation, keys, explain)
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	impl.WriteJsonResp(w, nil, evaluation, http.StatusOK)
+	return
+}
+
+// SaveHeartbeat stores the heartbeat of an installation, unknown fields are rejected so that installations
+// do not send more than the anonymized facts of common.Heartbeat
+func (impl *RestHandlerImpl) SaveHeartbeat(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, impl.telemetryConfig.MaxPayloadBytes))
+	decoder.DisallowUnknownFields()
+	heartbeat := &common.Heartbeat{}
+	err := decoder.Decode(heartbeat)
+	if err != nil {
+		impl.WriteJsonResp(w, err, "invalid heartbeat", http.StatusBadRequest)
+		return
+	}
+	err = impl.telemetryService.SaveHeartbeat(heartbeat)
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	impl.WriteJsonResp(w, nil, nil, http.StatusAccepted)
+	return
+}
+
+// getInstallationId returns the id of the calling installation, empty when it is not passed
+func getInstallationId(r *http.Request) string {
+	if installationId := r.URL.Query().Get("installationId"); len(installationId) > 0 {
+		return installationId
+	}
+	return r.Header.Get(InstallationIdHeader)
+}
+
+// getInstalledModules reads modules of the form name:version,name,... into a map of module name to version
+func getInstalledModules(modulesQueryParam string) (map[string]string, error) {
+	modules := make(map[string]string)
+	if len(modulesQueryParam) == 0 {
+		return modules, nil
+	}
+	for _, module := range strings.Split(modulesQueryParam, ",") {
+		name, version := module, ""
+		if index := strings.Index(module, ":"); index >= 0 {
+			name, version = module[:index], module[index+1:]
+		}
+		name, version = strings.TrimSpace(name), strings.TrimSpace(version)
+		if len(name) == 0 {
+			return nil, fmt.Errorf("invalid modules %s", modulesQueryParam)
+		}
+		modules[name] = version
+	}
+	return modules, nil
+}
+
+func (impl *RestHandlerImpl) ReleaseWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	impl.logger.Debug("release webhook handler received event")
+	// get git host Id and secret from request
+	vars := mux.Vars(r)
+	secretFromRequest := vars["secret"]
+	impl.logger.Debugw("secret found in request", "secret", secretFromRequest)
+
+	// validate signature
+	requestBodyBytes, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		impl.logger.Errorw("Cannot read the request body:", "err", err)
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+
+	isValidSig := impl.webhookSecretValidator.ValidateSecret(r, requestBodyBytes)
+	impl.logger.Debugw("Secret validation result ", "isValidSig", isValidSig)
+	if !isValidSig {
+		impl.logger.Error("Signature mismatch")
+		impl.WriteJsonResp(w, err, nil, http.StatusUnauthorized)
+		return
+	}
+	// validate event type
+	eventType := r.Header.Get(impl.client.GitHubConfig.GitHubEventTypeHeader)
+	impl.logger.Debugw("webhook event type header", "eventType : ", eventType)
+	if len(eventType) == 0 || eventType != bean.EventTypeRelease {
+		impl.logger.Errorw("Event type not known ", eventType)
+		impl.WriteJsonResp(w, err, nil, http.StatusBadRequest)
+		return
+	}
+
+	flag, err := impl.releaseNoteService.UpdateReleases(requestBodyBytes)
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	impl.WriteJsonResp(w, err, flag, http.StatusOK)
+	return
+}
+
+func (impl *RestHandlerImpl) GetModuleByName(w http.ResponseWriter, r *http.Request) {
+	impl.logger.Debug("get module meta info by name")
+	setupResponse(&w, r)
+	vars := mux.Vars(r)
+	name := vars["name"]
+	module, err := impl.releaseNoteService.GetModuleByName(name)
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	impl.WriteJsonResp(w, nil, module, http.StatusOK)
+	return
+}
+
+func (impl *RestHandlerImpl) GetDockerfileTemplateMetadata(w http.ResponseWriter, r *http.Request) {
+	impl.logger.Debug("get all dockerfile template metadata")
+	setupResponse(&w, r)
+	dockerfileTemplateMetadata := impl.ciBuildMetadataService.GetDockerfileTemplateMetadata()
+	impl.WriteJsonResp(w, nil, dockerfileTemplateMetadata, http.StatusOK)
+	return
+}
+func (impl *RestHandlerImpl) GetBuildpackMetadata(w http.ResponseWriter, r *http.Request) {
+	impl.logger.Debug("get all buildpack metadata")
+	setupResponse(&w, r)
+	buildpackMetadata := impl.ciBuildMetadataService.GetBuildpackMetadata()
+	impl.WriteJsonResp(w, nil, buildpackMetadata, http.StatusOK)
+	return
+}
+
+// StreamEvents streams release, catalog and metadata events as server-sent events until the client disconnects.
+// Clients resume with the Last-Event-ID header, or lastEventId query param, from the events still buffered.
+func (impl *RestHandlerImpl) StreamEvents(w http.ResponseWriter, r *http.Request) {
+	setupResponse(&w, r)
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		impl.WriteJsonResp(w, fmt.Errorf("streaming not supported"), nil, http.StatusInternalServerError)
+		return
+	}
+	var eventTypes []string
+	if types := r.URL.Query().Get("types"); len(types) > 0 {
+		for _, eventType := range strings.Split(types, ",") {
+			if eventType = strings.TrimSpace(eventType); len(eventType) > 0 {
+				eventTypes = append(eventTypes, eventType)
+			}
+		}
+	}
+	lastEventId := r.Header.Get("Last-Event-ID")
+	if len(lastEventId) == 0 {
+		lastEventId = r.URL.Query().Get("lastEventId")
+	}
+	subscription, replayEvents, err := impl.eventStreamService.Subscribe(eventTypes, lastEventId)
+	if err != nil {
+		impl.WriteJsonResp(w, err, nil, http.StatusInternalServerError)
+		return
+	}
+	defer impl.eventStreamService.Unsubscribe(subscription)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// disables response buffering of nginx ingress
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", impl.eventStreamConfig.ReconnectDelayInMillis)
+	installationId := getInstallationId(r)
+	for _, event := range replayEvents {
+		if event, visible := impl.getInstallationStreamEvent(event, installationId); visible {
+			writeStreamEvent(w, event)
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(time.Duration(impl.eventStreamConfig.HeartbeatIntervalInSecs) * time.Second)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event, ok := <-subscription.Events:
+			if !ok {
+				// disconnected for lagging behind, the client reconnects with its last event id
+				return
+			}
+			event, visible := impl.getInstallationStreamEvent(event, installationId)
+			if !visible {
+				continue
+			}
+			writeStreamEvent(w, event)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+		case <-r.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// getInstallationStreamEvent returns the event as sent to the installation, false when it is hidden. Release events
+// of releases whose rollout does not include the installation yet are hidden, and catalog updates carry the latest
+// release visible to the installation.
+func (impl *RestHandlerImpl) getInstallationStreamEvent(event *common.StreamEvent, installationId string) (*common.StreamEvent, bool) {
+	if bean.IsValidReleaseEventType(event.Type) {
+		releaseEvent := &common.ReleaseEvent{}
+		if err := json.Unmarshal(event.Data, releaseEvent); err != nil || releaseEvent.Release == nil {
+			return event, true
+		}
+		return event, impl.rolloutService.IsReleaseVisible(bean.Repository(releaseEvent.Repository), releaseEvent.Release, installationId)
+	}
+	if event.Type != bean.CatalogUpdatedEvent {
+		return event, true
+	}
+	catalogUpdate := &common.CatalogUpdate{}
+	if err := json.Unmarshal(event.Data, catalogUpdate); err != nil || len(catalogUpdate.LatestTag) == 0 {
+		return event, true
+	}
+	repository := bean.Repository(catalogUpdate.Repository)
+	release, err := impl.getVisibleReleaseByTag(repository, catalogUpdate.LatestTag, installationId)
+	if err == nil && release != nil {
+		return event, true
+	}
+	// the latest tag is still rolling out for the installation, or was deleted since
+	catalogUpdate.LatestTag = ""
+	release, err = impl.getLatestVisibleRelease(repository, bean.DefaultReleaseChannel, installationId)
+	if err != nil {
+		impl.logger.Errorw("error in getting latest visible release for catalog update", "repository", repository, "err", err)
+	} else if release != nil {
+		catalogUpdate.LatestTag = release.TagName
+	}
+	data, err := json.Marshal(catalogUpdate)
+	if err != nil {
+		impl.logger.Errorw("error in marshalling catalog update", "repository", repository, "err", err)
+		return event, false
+	}
+	return &common.StreamEvent{Id: event.Id, Type: event.Type, Data: data}, true
+}
+
+func writeStreamEvent(w http.ResponseWriter, event *common.StreamEvent) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.Id, event.Type, event.Data)
+}
+
+// getReleaseFilter reads the version, channel and prerelease query params of release list apis
+func getReleaseFilter(r *http.Request) (*pkg.ReleaseFilter, error) {
+	filter := &pkg.ReleaseFilter{
+		Channel: r.URL.Query().Get("channel"),
+	}
+	var err error
+	if serverVersion := r.URL.Query().Get("serverVersion"); len(serverVersion) > 0 {
+		filter.ServerVersion, err = semver.NewVersion(serverVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid serverVersion")
+		}
+	}
+	if versionRange := r.URL.Query().Get("versionRange"); len(versionRange) > 0 {
+		filter.VersionConstraint, err = semver.NewConstraint(versionRange)
+		if err != nil {
+			return nil, fmt.Errorf("invalid versionRange")
+		}
+	}
+	if includePrerelease := r.URL.Query().Get("includePrerelease"); len(includePrerelease) > 0 {
+		filter.IncludePrerelease, err = strconv.ParseBool(includePrerelease)
+		if err != nil {
+			return nil, fmt.Errorf("invalid includePrerelease")
+		}
+	}
+	return filter, nil
+}
+
+type releasePagination struct {
+	offset       int
+	size         int
+	hasSizeParam bool
+}
+
+func getReleasePagination(r *http.Request) (*releasePagination, error) {
+	pagination := &releasePagination{size: 10}
+	var err error
+	if offset := r.URL.Query().Get("offset"); len(offset) > 0 {
+		pagination.offset, err = strconv.Atoi(offset)
+		if err != nil || pagination.offset < 0 {
+			return nil, fmt.Errorf("invalid offset")
+		}
+	}
+	if size := r.URL.Query().Get("size"); len(size) > 0 {
+		pagination.hasSizeParam = true
+		pagination.size, err = strconv.Atoi(size)
+		if err != nil || pagination.size < 0 {
+			return nil, fmt.Errorf("invalid size")
+		}
+	}
+	return pagination, nil
+}
+
+// isApplicable tells if the releases are paginated. With a version filter they are only paginated
+// when size is explicitly provided, otherwise with the default or provided values.
+func (pagination *releasePagination) isApplicable(filter *pkg.ReleaseFilter) bool {
+	if filter.HasVersionFilter() {
+		return pagination.hasSizeParam && pagination.size > 0
+	}
+	return pagination.size > 0
+}
+
+func getReleaseTime(release *common.Release) time.Time {
+	if release.PublishedAt.IsZero() {
+		return release.CreatedAt
+	}
+	return release.PublishedAt
+}
+
+// getFromAndToVersion reads the from and to query params of a version range, to is optional
+func getFromAndToVersion(r *http.Request) (string, string, string, error) {
+	from := r.URL.Query().Get("from")
+	to := r.URL.Query().Get("to")
+	if _, err := semver.NewVersion(from); err != nil {
+		return from, to, "invalid from version", err
+	}
+	if len(to) > 0 {
+		if _, err := semver.NewVersion(to); err != nil {
+			return from, to, "invalid to version", err
+		}
+		if common.IsVersionNewer(from, to) {
+			return from, to, "from version is newer than to version", fmt.Errorf("from version %s is newer than to version %s", from, to)
+		}
+	}
+	return from, to, "", nil
+}
+
+// getReleasesInUpgradeOrder returns the releases newer than from and up to to (inclusive, latest when empty),
+// oldest first
+func getReleasesInUpgradeOrder(releases []*common.Release, from string, to string) []*common.Release {
+	releases, _ = common.SortReleasesBySemver(releases)
+	var rangeReleases []*common.Release
+	// releases are sorted newest first, walk them backwards to get them in upgrade order
+	for i := len(releases) - 1; i >= 0; i-- {
+		release := releases[i]
+		if !common.IsVersionNewer(release.TagName, from) {
+			continue
+		}
+		if len(to) > 0 && common.IsVersionNewer(release.TagName, to) {
+			break
+		}
+		rangeReleases = append(rangeReleases, release)
+	}
+	return rangeReleases
+}
+
+func getReleaseNoteFormat(r *http.Request) (bean.ReleaseNoteFormat, error) {
+	format := bean.ReleaseNoteFormat(r.URL.Query().Get("format"))
+	if len(format) == 0 {
+		return bean.ReleaseNoteFormatMarkdown, nil
+	}
+	if !bean.IsValidReleaseNoteFormat(format) {
+		return format, fmt.Errorf("unsupported format %s", format)
+	}
+	return format, nil
+}
+
+// getVisibleReleases returns the releases of a repository, releases under a staged rollout are hidden from
+// installations outside of its current percentage
+func (impl *RestHandlerImpl) getVisibleReleases(repository bean.Repository, installationId string) ([]*common.Release, error) {
+	releases, err := impl.releaseNoteService.GetReleases(repository)
+	if err != nil {
+		return nil, err
+	}
+	return impl.rolloutService.FilterReleases(repository, releases, installationId), nil
+}
+
+// getVisibleReleaseByTag returns nil for releases whose rollout does not include the installation yet
+func (impl *RestHandlerImpl) getVisibleReleaseByTag(repository bean.Repository, tagName string, installationId string) (*common.Release, error) {
+	release, err := impl.releaseNoteService.GetReleaseByTag(repository, tagName)
+	if err != nil || release == nil {
+		return nil, err
+	}
+	if !impl.rolloutService.IsReleaseVisible(repository, release, installationId) {
+		return nil, nil
+	}
+	return release, nil
+}
+
+// getLatestVisibleRelease falls back to the latest release of the channel which the installation is included
+// in, when the latest one is still rolling out
+func (impl *RestHandlerImpl) getLatestVisibleRelease(repository bean.Repository, channel string, installationId string) (*common.Release, error) {
+	release, err := impl.releaseNoteService.GetLatestRelease(repository, channel)
+	if err != nil || release == nil {
+		return nil, err
+	}
+	if impl.rolloutService.IsReleaseVisible(repository, release, installationId) {
+		return release, nil
+	}
+	releases, err := impl.getVisibleReleases(repository, installationId)
+	if err != nil {
+		return nil, err
+	}
+	sortedReleases, _ := common.SortReleasesBySemver(releases)
+	// same rules as the latest release index of the release note service
+	for _, sortedRelease := range sortedReleases {
+		if sortedRelease.Draft {
+			continue
+		}
+		if channel == bean.DefaultReleaseChannel && !sortedRelease.Prerelease {
+			return sortedRelease, nil
+		}
+		for _, releaseChannel := range sortedRelease.Channels {
+			if releaseChannel == channel {
+				return sortedRelease, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// renderReleases returns copies of the releases with body and prerequisites converted to the requested format,
+// releases held in cache are never modified
+func (impl *RestHandlerImpl) renderReleases(releases []*common.Release, format bean.ReleaseNoteFormat, repository bean.Repository) ([]*common.Release, error) {
+	if format == bean.ReleaseNoteFormatMarkdown {
+		return releases, nil
+	}
+	renderedReleases := make([]*common.Release, 0, len(releases))
+	for _, release := range releases {
+		renderedRelease := *release
+		var err error
+		renderedRelease.Body, err = impl.releaseNoteRenderer.Render(release.Body, format, repository)
+		if err != nil {
+			return nil, err
+		}
+		renderedRelease.PrerequisiteMessage, err = impl.releaseNoteRenderer.Render(release.PrerequisiteMessage, format, repository)
+		if err != nil {
+			return nil, err
+		}
+		renderedRelease.Prerequisites = make([]*common.Prerequisite, 0, len(release.Prerequisites))
+		for _, prerequisite := range release.Prerequisites {
+			renderedPrerequisite := *prerequisite
+			renderedPrerequisite.Body, err = impl.releaseNoteRenderer.Render(prerequisite.Body, format, repository)
+			if err != nil {
+				return nil, err
+			}
+			renderedRelease.Prerequisites = append(renderedRelease.Prerequisites, &renderedPrerequisite)
+		}
+		renderedReleases = append(renderedReleases, &renderedRelease)
+	}
+	return renderedReleases, nil
+}
+
+func paginate[T any](items []T, offset int, size int) []T {
+	if offset >= len(items) {
+		return items[:0]
+	}
+	if offset+size <= len(items) {
+		return items[offset : offset+size]
+	}
+	return items[offset:]
 }
@@ -25,13 +25,14 @@ import (
 )
 
 type MuxRouter struct {
-	logger      *zap.SugaredLogger
-	Router      *mux.Router
-	restHandler RestHandler
+	logger           *zap.SugaredLogger
+	Router           *mux.Router
+	restHandler      RestHandler
+	adminRestHandler AdminRestHandler
 }
 
-func NewMuxRouter(logger *zap.SugaredLogger, restHandler RestHandler) *MuxRouter {
-	return &MuxRouter{logger: logger, Router: mux.NewRouter(), restHandler: restHandler}
+func NewMuxRouter(logger *zap.SugaredLogger, restHandler RestHandler, adminRestHandler AdminRestHandler) *MuxRouter {
+	return &MuxRouter{logger: logger, Router: mux.NewRouter(), restHandler: restHandler, adminRestHandler: adminRestHandler}
 }
 
 func (r MuxRouter) Init() {
@@ -53,12 +54,55 @@ func (r MuxRouter) Init() {
 	})
 
 	r.Router.Path("/release/notes").HandlerFunc(r.restHandler.GetReleases).Methods("GET")
+	// registered ahead of /release/notes/{tag} so that search is not read as a tag
+	r.Router.Path("/release/notes/search").HandlerFunc(r.restHandler.SearchReleases).Methods("GET")
+	r.Router.Path("/release/notes/{tag}").HandlerFunc(r.restHandler.GetReleaseByTag).Methods("GET")
+	r.Router.Path("/release/notes/{tag}/images").HandlerFunc(r.restHandler.GetReleaseImages).Methods("GET")
+	r.Router.Path("/release/timeline").HandlerFunc(r.restHandler.GetReleaseTimeline).Methods("GET")
+	r.Router.Path("/release/compare").HandlerFunc(r.restHandler.CompareReleases).Methods("GET")
+	r.Router.Path("/release/latest").HandlerFunc(r.restHandler.GetLatestRelease).Methods("GET")
+	r.Router.Path("/release/feed.atom").HandlerFunc(r.restHandler.GetAtomFeed).Methods("GET")
+	r.Router.Path("/release/feed.rss").HandlerFunc(r.restHandler.GetRssFeed).Methods("GET")
+	r.Router.Path("/release/upgrade-path").HandlerFunc(r.restHandler.GetUpgradePath).Methods("GET")
+	r.Router.Path("/release/changelog").HandlerFunc(r.restHandler.GetChangelog).Methods("GET")
+	r.Router.Path("/release/breaking-changes").HandlerFunc(r.restHandler.GetBreakingChanges).Methods("GET")
+	r.Router.Path("/release/check").HandlerFunc(r.restHandler.CheckUpgrade).Methods("GET")
+	r.Router.Path("/release/compatibility").HandlerFunc(r.restHandler.GetCompatibilityMatrix).Methods("GET")
+	r.Router.Path("/release/metadata/validate").HandlerFunc(r.restHandler.ValidateReleaseMetadata).Methods("POST")
+	r.Router.Path("/release/prerequisites/validate").HandlerFunc(r.restHandler.ValidatePrerequisites).Methods("POST")
+	r.Router.Path("/security/advisories").HandlerFunc(r.restHandler.GetSecurityAdvisories).Methods("GET")
+	r.Router.Path("/announcements").HandlerFunc(r.restHandler.GetActiveAnnouncements).Methods("GET")
+	r.Router.Path("/flags").HandlerFunc(r.restHandler.GetFeatureFlags).Methods("GET")
+	r.Router.Path("/telemetry/heartbeat").HandlerFunc(r.restHandler.SaveHeartbeat).Methods("POST")
 	r.Router.Path("/release/webhook").HandlerFunc(r.restHandler.ReleaseWebhookHandler).Methods("POST")
 	r.Router.Path("/modules").HandlerFunc(r.restHandler.GetModules).Methods("GET")
 	r.Router.Path("/dockerfileTemplate").HandlerFunc(r.restHandler.GetDockerfileTemplateMetadata).Methods("GET")
 	r.Router.Path("/buildpackMetadata").HandlerFunc(r.restHandler.GetBuildpackMetadata).Methods("GET")
+	r.Router.Path("/events").HandlerFunc(r.restHandler.StreamEvents).Methods("GET")
 	r.Router.Path("/v2/modules").HandlerFunc(r.restHandler.GetModulesV2).Methods("GET")
 	r.Router.Path("/module").
 		Queries("name", "{name}").
 		HandlerFunc(r.restHandler.GetModuleByName).Methods("GET")
+
+	r.Router.Path("/admin/webhooks").HandlerFunc(r.adminRestHandler.GetWebhookSubscriptions).Methods("GET")
+	r.Router.Path("/admin/webhooks").HandlerFunc(r.adminRestHandler.CreateWebhookSubscription).Methods("POST")
+	r.Router.Path("/admin/webhooks/{id}").HandlerFunc(r.adminRestHandler.GetWebhookSubscription).Methods("GET")
+	r.Router.Path("/admin/webhooks/{id}").HandlerFunc(r.adminRestHandler.UpdateWebhookSubscription).Methods("PUT")
+	r.Router.Path("/admin/webhooks/{id}").HandlerFunc(r.adminRestHandler.DeleteWebhookSubscription).Methods("DELETE")
+	r.Router.Path("/admin/webhooks/{id}/deliveries").HandlerFunc(r.adminRestHandler.GetWebhookDeliveries).Methods("GET")
+	r.Router.Path("/admin/github-webhooks").HandlerFunc(r.adminRestHandler.GetGitHubWebhookStatus).Methods("GET")
+	r.Router.Path("/admin/github-webhooks/sync").HandlerFunc(r.adminRestHandler.SyncGitHubWebhooks).Methods("POST")
+	r.Router.Path("/admin/announcements").HandlerFunc(r.adminRestHandler.GetAnnouncements).Methods("GET")
+	r.Router.Path("/admin/announcements").HandlerFunc(r.adminRestHandler.CreateAnnouncement).Methods("POST")
+	r.Router.Path("/admin/announcements/{id}").HandlerFunc(r.adminRestHandler.GetAnnouncement).Methods("GET")
+	r.Router.Path("/admin/announcements/{id}").HandlerFunc(r.adminRestHandler.UpdateAnnouncement).Methods("PUT")
+	r.Router.Path("/admin/announcements/{id}").HandlerFunc(r.adminRestHandler.DeleteAnnouncement).Methods("DELETE")
+	r.Router.Path("/admin/telemetry/versions").HandlerFunc(r.adminRestHandler.GetVersionAdoption).Methods("GET")
+	r.Router.Path("/admin/telemetry/modules").HandlerFunc(r.adminRestHandler.GetModulePopularity).Methods("GET")
+	r.Router.Path("/admin/rollouts").HandlerFunc(r.adminRestHandler.GetRolloutPolicies).Methods("GET")
+	r.Router.Path("/admin/rollouts/{repo}/{tag}").HandlerFunc(r.adminRestHandler.GetRolloutPolicy).Methods("GET")
+	r.Router.Path("/admin/rollouts/{repo}/{tag}").HandlerFunc(r.adminRestHandler.SaveRolloutPolicy).Methods("PUT")
+	r.Router.Path("/admin/rollouts/{repo}/{tag}").HandlerFunc(r.adminRestHandler.DeleteRolloutPolicy).Methods("DELETE")
+	r.Router.Path("/admin/rollouts/{repo}/{tag}/advance").HandlerFunc(r.adminRestHandler.AdvanceRollout).Methods("POST")
+	r.Router.Path("/admin/rollouts/{repo}/{tag}/halt").HandlerFunc(r.adminRestHandler.HaltRollout).Methods("POST")
 }
@@ -0,0 +1,40 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+type AdminConfig struct {
+	// AdminApiToken is expected as bearer token on admin apis, admin apis are disabled when it is empty
+	AdminApiToken string `env:"ADMIN_API_TOKEN" envDefault:""`
+}
+
+func NewAdminConfig(logger *zap.SugaredLogger) (*AdminConfig, error) {
+	cfg := &AdminConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing admin config", "err", err)
+		return &AdminConfig{}, err
+	}
+	if len(cfg.AdminApiToken) == 0 {
+		logger.Warnw("admin api token is not configured, admin apis are disabled")
+	}
+	return cfg, nil
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+type AnnouncementConfig struct {
+	// AnnouncementFile persists the announcements, they are kept in memory only when it is empty
+	AnnouncementFile string `env:"ANNOUNCEMENT_FILE" envDefault:""`
+}
+
+func NewAnnouncementConfig(logger *zap.SugaredLogger) (*AnnouncementConfig, error) {
+	cfg := &AnnouncementConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing announcement config", "err", err)
+		return &AnnouncementConfig{}, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+type CiBuildMetadataConfig struct {
+	// ReloadIntervalInSecs is the interval metadata files are checked for changes at, 0 disables reloading
+	ReloadIntervalInSecs int `env:"CI_BUILD_METADATA_RELOAD_INTERVAL_IN_SECS" envDefault:"0"`
+}
+
+func NewCiBuildMetadataConfig(logger *zap.SugaredLogger) (*CiBuildMetadataConfig, error) {
+	cfg := &CiBuildMetadataConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing ci build metadata config", "err", err)
+		return &CiBuildMetadataConfig{}, err
+	}
+	return cfg, nil
+}
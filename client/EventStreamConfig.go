@@ -0,0 +1,67 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+type EventStreamConfig struct {
+	MaxSubscribers          int `env:"EVENT_STREAM_MAX_SUBSCRIBERS" envDefault:"500"`
+	HeartbeatIntervalInSecs int `env:"EVENT_STREAM_HEARTBEAT_INTERVAL_IN_SECS" envDefault:"15"`
+	// ReplayBufferSize is the number of latest events kept for subscribers resuming with Last-Event-ID
+	ReplayBufferSize int `env:"EVENT_STREAM_REPLAY_BUFFER_SIZE" envDefault:"256"`
+	// SubscriberBufferSize is the number of events a subscriber may lag behind before it is disconnected
+	SubscriberBufferSize   int `env:"EVENT_STREAM_SUBSCRIBER_BUFFER_SIZE" envDefault:"64"`
+	ReconnectDelayInMillis int `env:"EVENT_STREAM_RECONNECT_DELAY_IN_MILLIS" envDefault:"5000"`
+}
+
+func NewEventStreamConfig(logger *zap.SugaredLogger) (*EventStreamConfig, error) {
+	cfg := &EventStreamConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing event stream config", "err", err)
+		return &EventStreamConfig{}, err
+	}
+	err = cfg.validate()
+	if err != nil {
+		logger.Errorw("invalid event stream config", "err", err)
+		return &EventStreamConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (cfg *EventStreamConfig) validate() error {
+	if cfg.MaxSubscribers <= 0 {
+		return fmt.Errorf("EVENT_STREAM_MAX_SUBSCRIBERS should be positive, found %d", cfg.MaxSubscribers)
+	}
+	if cfg.HeartbeatIntervalInSecs <= 0 {
+		return fmt.Errorf("EVENT_STREAM_HEARTBEAT_INTERVAL_IN_SECS should be positive, found %d", cfg.HeartbeatIntervalInSecs)
+	}
+	if cfg.SubscriberBufferSize <= 0 {
+		return fmt.Errorf("EVENT_STREAM_SUBSCRIBER_BUFFER_SIZE should be positive, found %d", cfg.SubscriberBufferSize)
+	}
+	if cfg.ReplayBufferSize < 0 {
+		return fmt.Errorf("EVENT_STREAM_REPLAY_BUFFER_SIZE should not be negative, found %d", cfg.ReplayBufferSize)
+	}
+	if cfg.ReconnectDelayInMillis < 0 {
+		return fmt.Errorf("EVENT_STREAM_RECONNECT_DELAY_IN_MILLIS should not be negative, found %d", cfg.ReconnectDelayInMillis)
+	}
+	return nil
+}
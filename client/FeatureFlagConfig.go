@@ -0,0 +1,39 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+type FeatureFlagConfig struct {
+	// CatalogFile is the yaml catalog of feature flags, no flags are served when it is empty
+	CatalogFile string `env:"FEATURE_FLAG_CATALOG_FILE" envDefault:""`
+	// ReloadIntervalInSecs is the interval the catalog is checked for changes at, 0 disables reloading
+	ReloadIntervalInSecs int `env:"FEATURE_FLAG_RELOAD_INTERVAL_IN_SECS" envDefault:"0"`
+}
+
+func NewFeatureFlagConfig(logger *zap.SugaredLogger) (*FeatureFlagConfig, error) {
+	cfg := &FeatureFlagConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing feature flag config", "err", err)
+		return &FeatureFlagConfig{}, err
+	}
+	return cfg, nil
+}
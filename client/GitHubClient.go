@@ -18,6 +18,7 @@ package util
 
 import (
 	"context"
+	"fmt"
 	"github.com/caarlos0/env"
 	"github.com/google/go-github/github"
 	"go.uber.org/zap"
@@ -25,6 +26,7 @@ import (
 	http2 "net/http"
 	"net/url"
 	"path"
+	"strings"
 )
 
 const (
@@ -72,6 +74,20 @@ type GitHubConfig struct {
 type GitHubClient struct {
 	GitHubClient *github.Client
 	GitHubConfig *GitHubConfig
+	// Repositories are the repositories releases are served from, in order of configuration
+	Repositories      []*GitHubRepository
+	repositoryByAlias map[string]*GitHubRepository
+}
+
+// GitHubRepository is a configured repository along with the client for its host and credentials
+type GitHubRepository struct {
+	Alias  string
+	Owner  string
+	Name   string
+	Host   string
+	Client *github.Client
+	// Channels are the release channels of the repository, nil uses RELEASE_CHANNELS
+	Channels []*ReleaseChannel
 }
 
 /* #nosec */
@@ -82,16 +98,59 @@ func NewGitHubClient(logger *zap.SugaredLogger) (*GitHubClient, error) {
 		logger.Error("err", err)
 		return &GitHubClient{}, err
 	}
+	client, err := newGitHubApiClient(logger, cfg.GitHubHost, cfg.GitHubToken)
+	if err != nil {
+		return nil, err
+	}
+	gitHubClient := &GitHubClient{
+		GitHubClient:      client,
+		GitHubConfig:      cfg,
+		repositoryByAlias: make(map[string]*GitHubRepository),
+	}
+	repositoryConfigs, err := getRepositoryConfigs(logger, cfg)
+	if err != nil {
+		return nil, err
+	}
+	// repositories on the same host with the same token share a client
+	clients := map[string]*github.Client{cfg.GitHubHost + "|" + cfg.GitHubToken: client}
+	for _, repositoryConfig := range repositoryConfigs {
+		if _, ok := gitHubClient.repositoryByAlias[repositoryConfig.Alias]; ok {
+			return nil, fmt.Errorf("duplicate repository alias %s", repositoryConfig.Alias)
+		}
+		clientKey := repositoryConfig.Host + "|" + repositoryConfig.Token
+		repositoryClient, ok := clients[clientKey]
+		if !ok {
+			repositoryClient, err = newGitHubApiClient(logger, repositoryConfig.Host, repositoryConfig.Token)
+			if err != nil {
+				return nil, err
+			}
+			clients[clientKey] = repositoryClient
+		}
+		repository := &GitHubRepository{
+			Alias:    repositoryConfig.Alias,
+			Owner:    repositoryConfig.Owner,
+			Name:     repositoryConfig.Name,
+			Host:     repositoryConfig.Host,
+			Client:   repositoryClient,
+			Channels: repositoryConfig.Channels,
+		}
+		gitHubClient.Repositories = append(gitHubClient.Repositories, repository)
+		gitHubClient.repositoryByAlias[repository.Alias] = repository
+	}
+	return gitHubClient, nil
+}
+
+func newGitHubApiClient(logger *zap.SugaredLogger, host string, token string) (*github.Client, error) {
 	ctx := context.Background()
 	httpTransport := &http2.Transport{}
 	httpClient := &http2.Client{Transport: httpTransport}
 	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: cfg.GitHubToken},
+		&oauth2.Token{AccessToken: token},
 	)
 	ctx = context.WithValue(ctx, oauth2.HTTPClient, httpClient)
 	tc := oauth2.NewClient(ctx, ts)
 	var client *github.Client
-	hostUrl, err := url.Parse(cfg.GitHubHost)
+	hostUrl, err := url.Parse(host)
 	if err != nil {
 		logger.Errorw("error in creating git client ", "host", hostUrl, "err", err)
 		return nil, err
@@ -99,13 +158,52 @@ func NewGitHubClient(logger *zap.SugaredLogger) (*GitHubClient, error) {
 	if hostUrl.Host == GITHUB_HOST {
 		client = github.NewClient(tc)
 	} else {
-		logger.Infow("creating github EnterpriseClient with org", "host", cfg.GitHubHost, "org", cfg.GitHubOrg)
+		logger.Infow("creating github EnterpriseClient", "host", host)
 		hostUrl.Path = path.Join(hostUrl.Path, GITHUB_API_V3)
 		client, err = github.NewEnterpriseClient(hostUrl.String(), hostUrl.String(), tc)
 	}
-	gitHubClient := &GitHubClient{
-		GitHubClient: client,
-		GitHubConfig: cfg,
+	return client, err
+}
+
+// GetRepository returns the repository configured with the alias. Repositories which are not configured
+// resolve to the repository of that name in GITHUB_ORG on GITHUB_HOST.
+func (impl *GitHubClient) GetRepository(alias string) *GitHubRepository {
+	if repository, ok := impl.repositoryByAlias[alias]; ok {
+		return repository
+	}
+	return &GitHubRepository{
+		Alias:  alias,
+		Owner:  impl.GitHubConfig.GitHubOrg,
+		Name:   alias,
+		Host:   impl.GitHubConfig.GitHubHost,
+		Client: impl.GitHubClient,
+	}
+}
+
+func (impl *GitHubClient) IsConfiguredRepository(alias string) bool {
+	_, ok := impl.repositoryByAlias[alias]
+	return ok
+}
+
+// GetRepositoryByFullName returns the configured repository of owner/name, host disambiguates repositories
+// of the same name on different hosts and may be empty. Nil is returned when no repository matches.
+func (impl *GitHubClient) GetRepositoryByFullName(host string, owner string, name string) *GitHubRepository {
+	var matched *GitHubRepository
+	for _, repository := range impl.Repositories {
+		if !strings.EqualFold(repository.Owner, owner) || !strings.EqualFold(repository.Name, name) {
+			continue
+		}
+		if repositoryHostUrl, err := url.Parse(repository.Host); err == nil && repositoryHostUrl.Host == host {
+			return repository
+		}
+		if matched == nil {
+			matched = repository
+		}
 	}
-	return gitHubClient, err
+	return matched
+}
+
+// GetWebUrl returns the url of the repository on its github host
+func (repository *GitHubRepository) GetWebUrl() string {
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(repository.Host, "/"), repository.Owner, repository.Name)
 }
@@ -0,0 +1,61 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+// ReleaseChannel defines a release track. A release belongs to the channel when it matches
+// every rule which is set, empty rules match everything.
+type ReleaseChannel struct {
+	Name string `json:"name" yaml:"name"`
+	// TagPattern is a regex matched against the release tag
+	TagPattern string `json:"tagPattern,omitempty" yaml:"tagPattern"`
+	// VersionConstraint is a semver constraint, e.g. ">= 0.6.0" or "~0.7"
+	VersionConstraint string `json:"versionConstraint,omitempty" yaml:"versionConstraint"`
+	// Prerelease matches the github prerelease flag, nil matches both
+	Prerelease *bool `json:"prerelease,omitempty" yaml:"prerelease"`
+}
+
+type ReleaseChannelConfigVariables struct {
+	ReleaseChannels string `env:"RELEASE_CHANNELS" envDefault:"[{\"name\":\"stable\",\"tagPattern\":\"^v?[0-9]+\\\\.[0-9]+\\\\.[0-9]+$\",\"prerelease\":false},{\"name\":\"beta\",\"prerelease\":true},{\"name\":\"lts\",\"tagPattern\":\"(?i)lts\",\"prerelease\":false}]"`
+}
+
+type ReleaseChannelConfig struct {
+	Channels []*ReleaseChannel
+}
+
+func NewReleaseChannelConfig(logger *zap.SugaredLogger) (*ReleaseChannelConfig, error) {
+	cfg := &ReleaseChannelConfigVariables{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing release channel config", "err", err)
+		return &ReleaseChannelConfig{}, err
+	}
+	var channels []*ReleaseChannel
+	if len(cfg.ReleaseChannels) > 0 {
+		err = json.Unmarshal([]byte(cfg.ReleaseChannels), &channels)
+		if err != nil {
+			logger.Errorw("error on unmarshalling release channels", "releaseChannels", cfg.ReleaseChannels, "err", err)
+			return &ReleaseChannelConfig{}, err
+		}
+	}
+	return &ReleaseChannelConfig{Channels: channels}, nil
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+type ReleaseCompareConfig struct {
+	// MaxPullRequestLookups limits the github calls for pull requests of commits per comparison, pull requests
+	// of the remaining commits are read from their commit messages
+	MaxPullRequestLookups        int `env:"RELEASE_COMPARE_MAX_PR_LOOKUPS" envDefault:"100"`
+	PullRequestLookupConcurrency int `env:"RELEASE_COMPARE_PR_LOOKUP_CONCURRENCY" envDefault:"5"`
+	TimeoutInSecs                int `env:"RELEASE_COMPARE_TIMEOUT_IN_SECS" envDefault:"60"`
+	// CacheSize is the number of complete comparisons kept in memory, least recently used ones are evicted
+	CacheSize int `env:"RELEASE_COMPARE_CACHE_SIZE" envDefault:"200"`
+}
+
+func NewReleaseCompareConfig(logger *zap.SugaredLogger) (*ReleaseCompareConfig, error) {
+	cfg := &ReleaseCompareConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing release compare config", "err", err)
+		return &ReleaseCompareConfig{}, err
+	}
+	if cfg.TimeoutInSecs <= 0 {
+		return &ReleaseCompareConfig{}, fmt.Errorf("RELEASE_COMPARE_TIMEOUT_IN_SECS should be positive, found %d", cfg.TimeoutInSecs)
+	}
+	if cfg.CacheSize <= 0 {
+		return &ReleaseCompareConfig{}, fmt.Errorf("RELEASE_COMPARE_CACHE_SIZE should be positive, found %d", cfg.CacheSize)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+type ReleaseManifestConfig struct {
+	// ManifestAssetNames are the release asset names looked up for a component manifest, first match wins
+	ManifestAssetNames    []string `env:"RELEASE_MANIFEST_ASSET_NAMES" envDefault:"images.txt,images.yaml,images.yml" envSeparator:","`
+	ManifestMaxSizeBytes  int64    `env:"RELEASE_MANIFEST_MAX_SIZE_BYTES" envDefault:"1048576"`
+	ManifestTimeoutInSecs int      `env:"RELEASE_MANIFEST_TIMEOUT_IN_SECS" envDefault:"30"`
+	// ManifestCacheSize is the number of parsed manifests kept in memory, least recently used ones are evicted
+	ManifestCacheSize int `env:"RELEASE_MANIFEST_CACHE_SIZE" envDefault:"200"`
+}
+
+func NewReleaseManifestConfig(logger *zap.SugaredLogger) (*ReleaseManifestConfig, error) {
+	cfg := &ReleaseManifestConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing release manifest config", "err", err)
+		return &ReleaseManifestConfig{}, err
+	}
+	if cfg.ManifestMaxSizeBytes <= 0 {
+		return &ReleaseManifestConfig{}, fmt.Errorf("RELEASE_MANIFEST_MAX_SIZE_BYTES should be positive, found %d", cfg.ManifestMaxSizeBytes)
+	}
+	if cfg.ManifestTimeoutInSecs <= 0 {
+		return &ReleaseManifestConfig{}, fmt.Errorf("RELEASE_MANIFEST_TIMEOUT_IN_SECS should be positive, found %d", cfg.ManifestTimeoutInSecs)
+	}
+	if cfg.ManifestCacheSize <= 0 {
+		return &ReleaseManifestConfig{}, fmt.Errorf("RELEASE_MANIFEST_CACHE_SIZE should be positive, found %d", cfg.ManifestCacheSize)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+)
+
+// RepositoryConfig is a github repository releases are served from, empty fields fall back to GitHubConfig
+type RepositoryConfig struct {
+	// Alias is the value of the repo query param and the key of the repository in caches and blob, defaults to Name
+	Alias string `json:"alias,omitempty" yaml:"alias"`
+	Name  string `json:"name" yaml:"name"`
+	Owner string `json:"owner,omitempty" yaml:"owner"`
+	Host  string `json:"host,omitempty" yaml:"host"`
+	Token string `json:"token,omitempty" yaml:"token"`
+	// TokenEnv is the env variable holding the token, keeps tokens out of the config file
+	TokenEnv string `json:"tokenEnv,omitempty" yaml:"tokenEnv"`
+	// Channels replace the release channels of RELEASE_CHANNELS for the repository
+	Channels []*ReleaseChannel `json:"channels,omitempty" yaml:"channels"`
+}
+
+// RepositoryConfigVariables configure the repositories either as a yaml file or a json list, GITHUB_REPO
+// is used when neither is set
+type RepositoryConfigVariables struct {
+	RepositoryConfigFile string `env:"GITHUB_REPO_CONFIG_FILE" envDefault:""`
+	RepositoryConfig     string `env:"GITHUB_REPO_CONFIG" envDefault:""`
+}
+
+// repositoryConfigFile is the format of GITHUB_REPO_CONFIG_FILE
+type repositoryConfigFile struct {
+	Repositories []*RepositoryConfig `yaml:"repositories"`
+}
+
+func getRepositoryConfigs(logger *zap.SugaredLogger, gitHubConfig *GitHubConfig) ([]*RepositoryConfig, error) {
+	cfg := &RepositoryConfigVariables{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing repository config", "err", err)
+		return nil, err
+	}
+	var repositoryConfigs []*RepositoryConfig
+	switch {
+	case len(cfg.RepositoryConfigFile) > 0:
+		content, err := ioutil.ReadFile(cfg.RepositoryConfigFile)
+		if err != nil {
+			logger.Errorw("error in reading repository config file", "file", cfg.RepositoryConfigFile, "err", err)
+			return nil, err
+		}
+		configFile := &repositoryConfigFile{}
+		err = yaml.UnmarshalStrict(content, configFile)
+		if err != nil {
+			logger.Errorw("error in parsing repository config file", "file", cfg.RepositoryConfigFile, "err", err)
+			return nil, err
+		}
+		repositoryConfigs = configFile.Repositories
+	case len(cfg.RepositoryConfig) > 0:
+		err = json.Unmarshal([]byte(cfg.RepositoryConfig), &repositoryConfigs)
+		if err != nil {
+			logger.Errorw("error on unmarshalling repository config", "err", err)
+			return nil, err
+		}
+	default:
+		for _, repo := range gitHubConfig.GitHubRepo {
+			repositoryConfigs = append(repositoryConfigs, &RepositoryConfig{Name: repo})
+		}
+	}
+	for _, repositoryConfig := range repositoryConfigs {
+		if repositoryConfig == nil || len(repositoryConfig.Name) == 0 {
+			return nil, fmt.Errorf("repository name is required in repository config")
+		}
+		if len(repositoryConfig.Alias) == 0 {
+			repositoryConfig.Alias = repositoryConfig.Name
+		}
+		if len(repositoryConfig.Owner) == 0 {
+			repositoryConfig.Owner = gitHubConfig.GitHubOrg
+		}
+		if len(repositoryConfig.Host) == 0 {
+			repositoryConfig.Host = gitHubConfig.GitHubHost
+		}
+		if len(repositoryConfig.Token) == 0 && len(repositoryConfig.TokenEnv) > 0 {
+			repositoryConfig.Token = os.Getenv(repositoryConfig.TokenEnv)
+			if len(repositoryConfig.Token) == 0 {
+				logger.Warnw("token env of repository is empty", "repo", repositoryConfig.Alias, "tokenEnv", repositoryConfig.TokenEnv)
+			}
+		}
+		// GITHUB_TOKEN is only ever sent to GITHUB_HOST
+		if len(repositoryConfig.Token) == 0 && len(repositoryConfig.TokenEnv) == 0 && repositoryConfig.Host == gitHubConfig.GitHubHost {
+			repositoryConfig.Token = gitHubConfig.GitHubToken
+		}
+		for _, channel := range repositoryConfig.Channels {
+			if channel == nil || len(channel.Name) == 0 {
+				return nil, fmt.Errorf("release channel name is required for repository %s", repositoryConfig.Alias)
+			}
+		}
+	}
+	return repositoryConfigs, nil
+}
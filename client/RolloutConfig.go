@@ -0,0 +1,40 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+type RolloutConfig struct {
+	// PolicyFile persists the rollout policies, they are kept in memory only when it is empty
+	PolicyFile string `env:"ROLLOUT_POLICY_FILE" envDefault:""`
+	// DefaultStages are the percentages newly published releases are rolled out in, e.g. 5,25,100.
+	// Releases are announced to all installations right away when it is empty.
+	DefaultStages []int `env:"ROLLOUT_DEFAULT_STAGES" envDefault:"" envSeparator:","`
+}
+
+func NewRolloutConfig(logger *zap.SugaredLogger) (*RolloutConfig, error) {
+	cfg := &RolloutConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing rollout config", "err", err)
+		return &RolloutConfig{}, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+type SecurityAdvisoryConfig struct {
+	// AdvisoriesFile is a yaml or json file of advisories served instead of the github repository advisories
+	AdvisoriesFile     string `env:"SECURITY_ADVISORIES_FILE" envDefault:""`
+	CacheTtlInMins     int    `env:"SECURITY_ADVISORIES_CACHE_TTL_IN_MINS" envDefault:"60"`
+	FetchTimeoutInSecs int    `env:"SECURITY_ADVISORIES_FETCH_TIMEOUT_IN_SECS" envDefault:"30"`
+}
+
+func NewSecurityAdvisoryConfig(logger *zap.SugaredLogger) (*SecurityAdvisoryConfig, error) {
+	cfg := &SecurityAdvisoryConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing security advisory config", "err", err)
+		return &SecurityAdvisoryConfig{}, err
+	}
+	if cfg.CacheTtlInMins <= 0 {
+		return &SecurityAdvisoryConfig{}, fmt.Errorf("SECURITY_ADVISORIES_CACHE_TTL_IN_MINS should be positive, found %d", cfg.CacheTtlInMins)
+	}
+	if cfg.FetchTimeoutInSecs <= 0 {
+		return &SecurityAdvisoryConfig{}, fmt.Errorf("SECURITY_ADVISORIES_FETCH_TIMEOUT_IN_SECS should be positive, found %d", cfg.FetchTimeoutInSecs)
+	}
+	return cfg, nil
+}
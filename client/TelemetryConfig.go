@@ -0,0 +1,78 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+type TelemetryConfig struct {
+	// Sink stores the heartbeats, either file or postgres
+	Sink string `env:"TELEMETRY_SINK" envDefault:"file"`
+	// File is the file heartbeats are appended to by the file sink, they are kept in memory only when it is empty
+	File               string `env:"TELEMETRY_FILE" envDefault:""`
+	HeartbeatsPerHour  int    `env:"TELEMETRY_HEARTBEATS_PER_HOUR" envDefault:"6"`
+	MaxPayloadBytes    int64  `env:"TELEMETRY_MAX_PAYLOAD_BYTES" envDefault:"65536"`
+	ActiveWindowInDays int    `env:"TELEMETRY_ACTIVE_WINDOW_IN_DAYS" envDefault:"30"`
+	// MaxInstallations bounds the installations rate limited within an hour and the installations kept by the file
+	// sink, which drops the installations reporting least recently beyond it
+	MaxInstallations int `env:"TELEMETRY_MAX_INSTALLATIONS" envDefault:"100000"`
+	// FileCompactionIntervalInMins is how often the file sink rewrites the file to the latest heartbeats
+	FileCompactionIntervalInMins int `env:"TELEMETRY_FILE_COMPACTION_INTERVAL_IN_MINS" envDefault:"60"`
+
+	PgAddr     string `env:"PG_ADDR" envDefault:"127.0.0.1"`
+	PgPort     string `env:"PG_PORT" envDefault:"5432"`
+	PgUser     string `env:"PG_USER" envDefault:"postgres"`
+	PgPassword string `env:"PG_PASSWORD" envDefault:""`
+	PgDatabase string `env:"PG_DATABASE" envDefault:"central_api"`
+}
+
+func NewTelemetryConfig(logger *zap.SugaredLogger) (*TelemetryConfig, error) {
+	cfg := &TelemetryConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing telemetry config", "err", err)
+		return &TelemetryConfig{}, err
+	}
+	err = cfg.validate()
+	if err != nil {
+		logger.Errorw("invalid telemetry config", "err", err)
+		return &TelemetryConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (cfg *TelemetryConfig) validate() error {
+	if cfg.HeartbeatsPerHour <= 0 {
+		return fmt.Errorf("TELEMETRY_HEARTBEATS_PER_HOUR should be positive, found %d", cfg.HeartbeatsPerHour)
+	}
+	if cfg.MaxPayloadBytes <= 0 {
+		return fmt.Errorf("TELEMETRY_MAX_PAYLOAD_BYTES should be positive, found %d", cfg.MaxPayloadBytes)
+	}
+	if cfg.ActiveWindowInDays <= 0 {
+		return fmt.Errorf("TELEMETRY_ACTIVE_WINDOW_IN_DAYS should be positive, found %d", cfg.ActiveWindowInDays)
+	}
+	if cfg.MaxInstallations <= 0 {
+		return fmt.Errorf("TELEMETRY_MAX_INSTALLATIONS should be positive, found %d", cfg.MaxInstallations)
+	}
+	if cfg.FileCompactionIntervalInMins <= 0 {
+		return fmt.Errorf("TELEMETRY_FILE_COMPACTION_INTERVAL_IN_MINS should be positive, found %d", cfg.FileCompactionIntervalInMins)
+	}
+	return nil
+}
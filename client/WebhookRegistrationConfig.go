@@ -0,0 +1,42 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+type WebhookRegistrationConfig struct {
+	// Enabled makes sure on startup that every repo of GITHUB_REPO has a release webhook pointing at PublicUrl
+	Enabled bool `env:"GITHUB_WEBHOOK_REGISTRATION_ENABLED" envDefault:"false"`
+	// PublicUrl is the url github delivers release events to, the public address of /release/webhook
+	PublicUrl string `env:"GITHUB_WEBHOOK_PUBLIC_URL" envDefault:""`
+	// SyncIntervalInMins re-checks the webhooks for drift periodically, 0 checks only on startup
+	SyncIntervalInMins int `env:"GITHUB_WEBHOOK_SYNC_INTERVAL_IN_MINS" envDefault:"0"`
+	TimeoutInSecs      int `env:"GITHUB_WEBHOOK_REGISTRATION_TIMEOUT_IN_SECS" envDefault:"30"`
+}
+
+func NewWebhookRegistrationConfig(logger *zap.SugaredLogger) (*WebhookRegistrationConfig, error) {
+	cfg := &WebhookRegistrationConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing webhook registration config", "err", err)
+		return &WebhookRegistrationConfig{}, err
+	}
+	return cfg, nil
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"github.com/caarlos0/env"
+	"go.uber.org/zap"
+)
+
+type WebhookSubscriptionConfig struct {
+	// SubscriptionFile persists the subscriptions, they are kept in memory only when it is empty
+	SubscriptionFile      string `env:"WEBHOOK_SUBSCRIPTION_FILE" envDefault:""`
+	DeliveryWorkers       int    `env:"WEBHOOK_DELIVERY_WORKERS" envDefault:"4"`
+	DeliveryQueueSize     int    `env:"WEBHOOK_DELIVERY_QUEUE_SIZE" envDefault:"1000"`
+	DeliveryMaxAttempts   int    `env:"WEBHOOK_DELIVERY_MAX_ATTEMPTS" envDefault:"5"`
+	DeliveryBackoffInSecs int    `env:"WEBHOOK_DELIVERY_BACKOFF_IN_SECS" envDefault:"5"`
+	DeliveryTimeoutInSecs int    `env:"WEBHOOK_DELIVERY_TIMEOUT_IN_SECS" envDefault:"10"`
+	DeliveryHistorySize   int    `env:"WEBHOOK_DELIVERY_HISTORY_SIZE" envDefault:"100"`
+}
+
+func NewWebhookSubscriptionConfig(logger *zap.SugaredLogger) (*WebhookSubscriptionConfig, error) {
+	cfg := &WebhookSubscriptionConfig{}
+	err := env.Parse(cfg)
+	if err != nil {
+		logger.Errorw("error on parsing webhook subscription config", "err", err)
+		return &WebhookSubscriptionConfig{}, err
+	}
+	err = cfg.validate()
+	if err != nil {
+		logger.Errorw("invalid webhook subscription config", "err", err)
+		return &WebhookSubscriptionConfig{}, err
+	}
+	return cfg, nil
+}
+
+func (cfg *WebhookSubscriptionConfig) validate() error {
+	if cfg.DeliveryWorkers <= 0 {
+		return fmt.Errorf("WEBHOOK_DELIVERY_WORKERS should be positive, found %d", cfg.DeliveryWorkers)
+	}
+	if cfg.DeliveryQueueSize <= 0 {
+		return fmt.Errorf("WEBHOOK_DELIVERY_QUEUE_SIZE should be positive, found %d", cfg.DeliveryQueueSize)
+	}
+	if cfg.DeliveryMaxAttempts <= 0 {
+		return fmt.Errorf("WEBHOOK_DELIVERY_MAX_ATTEMPTS should be positive, found %d", cfg.DeliveryMaxAttempts)
+	}
+	if cfg.DeliveryBackoffInSecs <= 0 {
+		return fmt.Errorf("WEBHOOK_DELIVERY_BACKOFF_IN_SECS should be positive, found %d", cfg.DeliveryBackoffInSecs)
+	}
+	if cfg.DeliveryTimeoutInSecs <= 0 {
+		return fmt.Errorf("WEBHOOK_DELIVERY_TIMEOUT_IN_SECS should be positive, found %d", cfg.DeliveryTimeoutInSecs)
+	}
+	if cfg.DeliveryHistorySize <= 0 {
+		return fmt.Errorf("WEBHOOK_DELIVERY_HISTORY_SIZE should be positive, found %d", cfg.DeliveryHistorySize)
+	}
+	return nil
+}
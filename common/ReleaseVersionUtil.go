@@ -0,0 +1,60 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package common
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"sort"
+)
+
+// SortReleasesBySemver returns a copy of releases ordered newest first by semver. Releases whose tag is not
+// a valid semver are kept at the end in their original order and their tags are returned separately.
+func SortReleasesBySemver(releases []*Release) ([]*Release, []string) {
+	versions := make(map[*Release]*semver.Version, len(releases))
+	var semverReleases, nonSemverReleases []*Release
+	var nonSemverTags []string
+	for _, release := range releases {
+		version, err := semver.NewVersion(release.TagName)
+		if err != nil {
+			nonSemverReleases = append(nonSemverReleases, release)
+			nonSemverTags = append(nonSemverTags, release.TagName)
+			continue
+		}
+		versions[release] = version
+		semverReleases = append(semverReleases, release)
+	}
+	sort.SliceStable(semverReleases, func(i, j int) bool {
+		return versions[semverReleases[i]].GreaterThan(versions[semverReleases[j]])
+	})
+	return append(semverReleases, nonSemverReleases...), nonSemverTags
+}
+
+// IsVersionNewer compares two version strings and returns true if v1 is newer than v2.
+// Pre-release versions follow semver precedence, so v0.7.0-rc.1 is older than v0.7.0
+// but newer than v0.6.x, and build metadata is ignored.
+// Versions which are not valid semver are never considered newer.
+func IsVersionNewer(v1, v2 string) bool {
+	ver1, err := semver.NewVersion(v1)
+	if err != nil {
+		return false
+	}
+	ver2, err := semver.NewVersion(v2)
+	if err != nil {
+		return false
+	}
+	return ver1.GreaterThan(ver2)
+}
@@ -37,14 +37,473 @@ type ReleaseList struct {
 }
 
 type Release struct {
-	TagName             string    `json:"tagName"`
-	ReleaseName         string    `json:"releaseName"`
-	CreatedAt           time.Time `json:"createdAt"`
-	PublishedAt         time.Time `json:"publishedAt"`
-	Body                string    `json:"body"`
-	Prerequisite        bool      `json:"prerequisite"`
-	PrerequisiteMessage string    `json:"prerequisiteMessage"`
-	TagLink             string    `json:"tagLink"`
+	TagName             string            `json:"tagName"`
+	ReleaseName         string            `json:"releaseName"`
+	CreatedAt           time.Time         `json:"createdAt"`
+	PublishedAt         time.Time         `json:"publishedAt"`
+	Body                string            `json:"body"`
+	Prerequisite        bool              `json:"prerequisite"`
+	PrerequisiteMessage string            `json:"prerequisiteMessage"`
+	Prerequisites       []*Prerequisite   `json:"prerequisites"`
+	TagLink             string            `json:"tagLink"`
+	Prerelease          bool              `json:"prerelease"`
+	Draft               bool              `json:"draft"`
+	Channels            []string          `json:"channels"`
+	MandatoryUpgrade    bool              `json:"mandatoryUpgrade"`
+	Sections            []*ReleaseSection `json:"sections"`
+	Assets              []*ReleaseAsset   `json:"assets"`
+	BreakingChanges     []*ChangelogItem  `json:"breakingChanges"`
+	Deprecations        []*ChangelogItem  `json:"deprecations"`
+	// Compatibility is read from the release metadata block of the body, nil when there is none or it is invalid
+	Compatibility *ReleaseCompatibility `json:"compatibility"`
+}
+
+// ReleaseCompatibility is the release metadata declared by release managers, see bean.ReleaseMetadataMatcher
+type ReleaseCompatibility struct {
+	// KubernetesVersions is a semver constraint of the supported kubernetes versions, e.g. ">= 1.24, < 1.30"
+	KubernetesVersions string `json:"kubernetesVersions,omitempty" yaml:"kubernetesVersions"`
+	HelmChartVersion   string `json:"helmChartVersion,omitempty" yaml:"helmChartVersion"`
+	// MinModuleVersions are the minimum versions of modules required by the release keyed on module name
+	MinModuleVersions map[string]string `json:"minModuleVersions,omitempty" yaml:"minModuleVersions"`
+	// EndOfLife is the date support of the release ends on, formatted as bean.ReleaseMetadataDateLayout
+	EndOfLife string `json:"endOfLife,omitempty" yaml:"endOfLife"`
+}
+
+type ReleaseMetadataValidationRequest struct {
+	Body string `json:"body"`
+}
+
+type ReleaseMetadataValidationResult struct {
+	Valid         bool                  `json:"valid"`
+	Compatibility *ReleaseCompatibility `json:"compatibility"`
+	Errors        []string              `json:"errors"`
+	Warnings      []string              `json:"warnings"`
+}
+
+// SecurityAdvisory is a published security advisory of a repository, read from github or the advisories file
+type SecurityAdvisory struct {
+	Id          string    `json:"id" yaml:"id"`
+	CveId       string    `json:"cveId,omitempty" yaml:"cveId"`
+	Summary     string    `json:"summary" yaml:"summary"`
+	Severity    string    `json:"severity" yaml:"severity"`
+	Url         string    `json:"url" yaml:"url"`
+	PublishedAt time.Time `json:"publishedAt" yaml:"publishedAt"`
+	Repository  string    `json:"repository" yaml:"repository"`
+	// VulnerableVersionRange is a semver constraint of the affected versions, e.g. ">= 0.6.0, < 0.6.25"
+	VulnerableVersionRange string   `json:"vulnerableVersionRange" yaml:"vulnerableVersionRange"`
+	PatchedVersions        []string `json:"patchedVersions" yaml:"patchedVersions"`
+	// FixedIn is the oldest published release out of the vulnerable range, empty when there is none yet
+	FixedIn string `json:"fixedIn" yaml:"-"`
+}
+
+type SecurityAdvisories struct {
+	Version    string              `json:"version,omitempty"`
+	Advisories []*SecurityAdvisory `json:"advisories"`
+}
+
+// UpgradeCheckRequest is the state of an installation checking for upgrades, modules map installed module
+// names to their version which may be empty
+type UpgradeCheckRequest struct {
+	InstallationId    string
+	CurrentVersion    string
+	Channel           string
+	IncludePrerelease bool
+	Modules           map[string]string
+}
+
+type UpgradeCheck struct {
+	CurrentVersion   string `json:"currentVersion"`
+	LatestVersion    string `json:"latestVersion"`
+	UpgradeAvailable bool   `json:"upgradeAvailable"`
+	ReleasesBehind   int    `json:"releasesBehind"`
+	// PendingReleases are the tags newer than the current version, newest first
+	PendingReleases         []string `json:"pendingReleases"`
+	SecurityFixPending      bool     `json:"securityFixPending"`
+	PrerequisitePending     bool     `json:"prerequisitePending"`
+	MandatoryUpgradePending bool     `json:"mandatoryUpgradePending"`
+	EndOfLifeReached        bool     `json:"endOfLifeReached"`
+	// ModuleRequirements are the minimum module versions of pending releases the installed modules do not meet
+	ModuleRequirements []*ModuleRequirement `json:"moduleRequirements"`
+	Severity           string               `json:"severity"`
+}
+
+type ModuleRequirement struct {
+	Module           string `json:"module"`
+	InstalledVersion string `json:"installedVersion"`
+	MinVersion       string `json:"minVersion"`
+	TagName          string `json:"tagName"`
+}
+
+type CompatibilityMatrix struct {
+	KubernetesVersion string                `json:"k8sVersion,omitempty"`
+	Releases          []*CompatibilityEntry `json:"releases"`
+}
+
+// CompatibilityEntry is a release of the compatibility matrix
+type CompatibilityEntry struct {
+	TagName string `json:"tagName"`
+	*ReleaseCompatibility
+	EndOfLifeReached bool `json:"endOfLifeReached"`
+}
+
+// ReleaseEvent is raised on a release published or edited on github, see bean.ReleaseEventPublished
+type ReleaseEvent struct {
+	Id         string    `json:"id"`
+	Type       string    `json:"event"`
+	Repository string    `json:"repository"`
+	Release    *Release  `json:"release"`
+	CreatedOn  time.Time `json:"createdOn"`
+}
+
+// StreamEvent is an event of the /events stream, ids increase monotonically per server start
+type StreamEvent struct {
+	Id   uint64
+	Type string
+	// Data is the json payload of the event
+	Data []byte
+}
+
+type CatalogUpdate struct {
+	Repository   string `json:"repository"`
+	LatestTag    string `json:"latestTag"`
+	ReleaseCount int    `json:"releaseCount"`
+}
+
+type MetadataReload struct {
+	ReloadedOn time.Time `json:"reloadedOn"`
+}
+
+// WebhookSubscription is a target notified of release events, empty filters match everything
+type WebhookSubscription struct {
+	Id           string    `json:"id"`
+	Url          string    `json:"url"`
+	Events       []string  `json:"events"`
+	Repositories []string  `json:"repositories"`
+	Channels     []string  `json:"channels"`
+	Secret       string    `json:"secret,omitempty"`
+	Active       bool      `json:"active"`
+	CreatedOn    time.Time `json:"createdOn"`
+	UpdatedOn    time.Time `json:"updatedOn"`
+}
+
+type WebhookSubscriptionRequest struct {
+	Url          string   `json:"url"`
+	Events       []string `json:"events"`
+	Repositories []string `json:"repositories"`
+	Channels     []string `json:"channels"`
+	// Secret signs the payloads, it is kept on update when empty
+	Secret string `json:"secret"`
+	Active *bool  `json:"active"`
+}
+
+// Announcement is a banner shown inside devtron installations during its schedule window,
+// empty targeting fields match every installation
+type Announcement struct {
+	Id      string `json:"id"`
+	Type    string `json:"type"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	Link    string `json:"link,omitempty"`
+	// StartsAt and EndsAt bound the schedule window, either end may be open
+	StartsAt *time.Time `json:"startsAt,omitempty"`
+	EndsAt   *time.Time `json:"endsAt,omitempty"`
+	// VersionConstraint is a semver constraint of the targeted devtron versions, e.g. ">= 0.6.0, < 0.7.0"
+	VersionConstraint string   `json:"versionConstraint,omitempty"`
+	Editions          []string `json:"editions"`
+	// Modules targets installations having all of the modules installed, like the modules of feature flag rules
+	Modules   []string  `json:"modules"`
+	Active    bool      `json:"active"`
+	CreatedOn time.Time `json:"createdOn"`
+	UpdatedOn time.Time `json:"updatedOn"`
+}
+
+type AnnouncementRequest struct {
+	Type              string     `json:"type"`
+	Title             string     `json:"title"`
+	Message           string     `json:"message"`
+	Link              string     `json:"link"`
+	StartsAt          *time.Time `json:"startsAt"`
+	EndsAt            *time.Time `json:"endsAt"`
+	VersionConstraint string     `json:"versionConstraint"`
+	Editions          []string   `json:"editions"`
+	Modules           []string   `json:"modules"`
+	Active            *bool      `json:"active"`
+}
+
+// InstallationContext describes the installation announcements and feature flags are requested for
+type InstallationContext struct {
+	InstallationId string
+	Version        string
+	Edition        string
+	Modules        []string
+}
+
+// FeatureFlagEvaluation holds the values of the feature flags for an installation keyed on flag key
+type FeatureFlagEvaluation struct {
+	CatalogVersion string                    `json:"catalogVersion"`
+	Flags          map[string]interface{}    `json:"flags"`
+	Explanations   []*FeatureFlagExplanation `json:"explanations,omitempty"`
+}
+
+// FeatureFlagExplanation tells how the value of a flag was chosen, steps list the outcome of each rule
+type FeatureFlagExplanation struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Reason string      `json:"reason"`
+	Rule   string      `json:"rule,omitempty"`
+	Steps  []string    `json:"steps"`
+}
+
+// Heartbeat is the periodic report of an installation, cluster facts are anonymized by the installation
+type Heartbeat struct {
+	InstallationId string             `json:"installationId"`
+	Version        string             `json:"version"`
+	Edition        string             `json:"edition,omitempty"`
+	Modules        []*HeartbeatModule `json:"modules"`
+	Cluster        *ClusterFacts      `json:"cluster,omitempty"`
+	ReceivedAt     time.Time          `json:"receivedAt"`
+}
+
+// HeartbeatModule is an installed module, name is one of the modules of /v2/modules
+type HeartbeatModule struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+type ClusterFacts struct {
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+	// Provider is the kind of cluster like eks, gke, aks or k3s
+	Provider  string `json:"provider,omitempty"`
+	NodeCount int    `json:"nodeCount,omitempty"`
+}
+
+// VersionAdoption counts the installations active within the window by version, newest version first
+type VersionAdoption struct {
+	WindowInDays        int                      `json:"windowInDays"`
+	ActiveInstallations int                      `json:"activeInstallations"`
+	Versions            []*InstallationAggregate `json:"versions"`
+}
+
+// ModulePopularity counts the installations active within the window by installed module, most installed first
+type ModulePopularity struct {
+	WindowInDays        int                      `json:"windowInDays"`
+	ActiveInstallations int                      `json:"activeInstallations"`
+	Modules             []*InstallationAggregate `json:"modules"`
+}
+
+type InstallationAggregate struct {
+	Name          string  `json:"name"`
+	Installations int     `json:"installations"`
+	Percentage    float64 `json:"percentage"`
+}
+
+// RolloutPolicy announces a release to a growing percentage of installations stage by stage, installations are
+// bucketed by their installation id
+type RolloutPolicy struct {
+	Repository string `json:"repository"`
+	TagName    string `json:"tagName"`
+	// Stages are the percentages of installations of each stage, ascending up to 100
+	Stages       []int `json:"stages"`
+	CurrentStage int   `json:"currentStage"`
+	// Halted hides the release from all installations until the rollout is advanced again
+	Halted bool `json:"halted"`
+	// Percentage is the share of installations the release is announced to right now
+	Percentage int       `json:"percentage"`
+	CreatedOn  time.Time `json:"createdOn"`
+	UpdatedOn  time.Time `json:"updatedOn"`
+}
+
+type RolloutPolicyRequest struct {
+	Stages       []int `json:"stages"`
+	CurrentStage int   `json:"currentStage"`
+}
+
+// WebhookDelivery is an attempt of delivering an event to a subscription, all attempts of an event share the id
+type WebhookDelivery struct {
+	Id             string    `json:"id"`
+	SubscriptionId string    `json:"subscriptionId"`
+	EventId        string    `json:"eventId"`
+	Event          string    `json:"event"`
+	Attempt        int       `json:"attempt"`
+	StatusCode     int       `json:"statusCode,omitempty"`
+	Error          string    `json:"error,omitempty"`
+	Success        bool      `json:"success"`
+	DurationMs     int64     `json:"durationMs"`
+	AttemptedOn    time.Time `json:"attemptedOn"`
+}
+
+// WebhookRegistrationStatus is the result of the latest check of the github release webhooks
+type WebhookRegistrationStatus struct {
+	Enabled      bool                       `json:"enabled"`
+	PublicUrl    string                     `json:"publicUrl,omitempty"`
+	LastSyncedOn *time.Time                 `json:"lastSyncedOn,omitempty"`
+	Repositories []*RepositoryWebhookStatus `json:"repositories"`
+}
+
+type RepositoryWebhookStatus struct {
+	Repository string `json:"repository"`
+	HookId     int64  `json:"hookId,omitempty"`
+	State      string `json:"state"`
+	// Drift lists the differences found on the existing webhook, repaired unless State is failed
+	Drift     []string   `json:"drift,omitempty"`
+	Error     string     `json:"error,omitempty"`
+	CheckedOn *time.Time `json:"checkedOn,omitempty"`
+}
+
+// TimelineRelease is a release of the multi repository timeline, release fields are inlined
+type TimelineRelease struct {
+	Repository string `json:"repository"`
+	*Release
+}
+
+type ReleaseAsset struct {
+	Id            int64  `json:"id"`
+	Name          string `json:"name"`
+	Size          int    `json:"size"`
+	DownloadUrl   string `json:"downloadUrl"`
+	ContentType   string `json:"contentType"`
+	DownloadCount int    `json:"downloadCount"`
+}
+
+// ReleaseComponentManifest lists the container images shipped with a release, parsed from a release asset
+type ReleaseComponentManifest struct {
+	TagName string            `json:"tagName"`
+	Source  string            `json:"source"`
+	Images  []*ComponentImage `json:"images"`
+}
+
+type ComponentImage struct {
+	Name       string `json:"name"`
+	Image      string `json:"image"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+}
+
+type ReleaseSectionType string
+
+const (
+	ReleaseSectionFeatures      ReleaseSectionType = "features"
+	ReleaseSectionEnhancements  ReleaseSectionType = "enhancements"
+	ReleaseSectionBugFixes      ReleaseSectionType = "bugFixes"
+	ReleaseSectionDocumentation ReleaseSectionType = "documentation"
+	ReleaseSectionOthers        ReleaseSectionType = "others"
+)
+
+// ReleaseSectionOrder is the order in which sections are merged into a changelog
+var ReleaseSectionOrder = []ReleaseSectionType{ReleaseSectionFeatures, ReleaseSectionEnhancements, ReleaseSectionBugFixes, ReleaseSectionDocumentation, ReleaseSectionOthers}
+
+type ReleaseSection struct {
+	Type  ReleaseSectionType `json:"type"`
+	Title string             `json:"title"`
+	Items []*ChangelogItem   `json:"items"`
+}
+
+type ChangelogItem struct {
+	Text    string `json:"text"`
+	TagName string `json:"tagName,omitempty"`
+}
+
+type Changelog struct {
+	From     string            `json:"from"`
+	To       string            `json:"to"`
+	Releases []string          `json:"releases"`
+	Sections []*ReleaseSection `json:"sections"`
+}
+
+// BreakingChanges lists the breaking changes and deprecations of the releases of an upgrade range, releases
+// are the tags having any of them
+type BreakingChanges struct {
+	From            string           `json:"from"`
+	To              string           `json:"to"`
+	Releases        []string         `json:"releases"`
+	BreakingChanges []*ChangelogItem `json:"breakingChanges"`
+	Deprecations    []*ChangelogItem `json:"deprecations"`
+}
+
+type ReleaseSearchResult struct {
+	TagName      string    `json:"tagName"`
+	ReleaseName  string    `json:"releaseName"`
+	PublishedAt  time.Time `json:"publishedAt"`
+	TagLink      string    `json:"tagLink"`
+	Score        float64   `json:"score"`
+	Snippet      string    `json:"snippet"`
+	MatchedTerms []string  `json:"matchedTerms"`
+}
+
+// ReleaseComparison holds the commits and merged pull requests between two release tags
+type ReleaseComparison struct {
+	Base         string `json:"base"`
+	Head         string `json:"head"`
+	Status       string `json:"status"`
+	AheadBy      int    `json:"aheadBy"`
+	BehindBy     int    `json:"behindBy"`
+	TotalCommits int    `json:"totalCommits"`
+	// Truncated is set when github returned only part of the commits
+	Truncated    bool                     `json:"truncated"`
+	CompareUrl   string                   `json:"compareUrl"`
+	Commits      []*ComparisonCommit      `json:"commits"`
+	PullRequests []*ComparisonPullRequest `json:"pullRequests"`
+}
+
+type ComparisonCommit struct {
+	Sha          string    `json:"sha"`
+	Summary      string    `json:"summary"`
+	AuthorName   string    `json:"authorName"`
+	AuthorLogin  string    `json:"authorLogin,omitempty"`
+	Date         time.Time `json:"date"`
+	Url          string    `json:"url"`
+	PullRequests []int     `json:"pullRequests"`
+}
+
+type ComparisonPullRequest struct {
+	Number      int        `json:"number"`
+	Title       string     `json:"title"`
+	Url         string     `json:"url,omitempty"`
+	AuthorLogin string     `json:"authorLogin,omitempty"`
+	MergedAt    *time.Time `json:"mergedAt,omitempty"`
+}
+
+type UpgradePath struct {
+	From              string                 `json:"from"`
+	To                string                 `json:"to"`
+	Releases          []*Release             `json:"releases"`
+	Prerequisites     []*UpgradePrerequisite `json:"prerequisites"`
+	MandatoryReleases []string               `json:"mandatoryReleases"`
+}
+
+type UpgradePrerequisite struct {
+	TagName string          `json:"tagName"`
+	Message string          `json:"message"`
+	Items   []*Prerequisite `json:"items"`
+}
+
+const (
+	PrerequisiteSeverityInfo     = "info"
+	PrerequisiteSeverityWarning  = "warning"
+	PrerequisiteSeverityCritical = "critical"
+)
+
+func IsValidPrerequisiteSeverity(severity string) bool {
+	return severity == PrerequisiteSeverityInfo || severity == PrerequisiteSeverityWarning || severity == PrerequisiteSeverityCritical
+}
+
+type Prerequisite struct {
+	Id       string `json:"id" yaml:"id"`
+	Severity string `json:"severity" yaml:"severity"`
+	Title    string `json:"title" yaml:"title"`
+	Body     string `json:"body" yaml:"body"`
+	DocLink  string `json:"docLink,omitempty" yaml:"docLink"`
+}
+
+type PrerequisiteValidationRequest struct {
+	Body string `json:"body"`
+}
+
+type PrerequisiteValidationResult struct {
+	Valid         bool            `json:"valid"`
+	Prerequisites []*Prerequisite `json:"prerequisites"`
+	Errors        []string        `json:"errors"`
 }
 
 const MODULE_CICD = "cicd"
@@ -19,6 +19,8 @@ package util
 import (
 	"fmt"
 	"github.com/go-pg/pg"
+	"net/http"
+	"strconv"
 )
 
 type ApiError struct {
@@ -43,6 +45,24 @@ func (e ApiError) ErrorfUser(format string, a ...interface{}) error {
 	return &ApiError{InternalMessage: fmt.Sprintf(format, a...)}
 }
 
+// NewApiError returns an error responded with the given http status, the message is shown to the user as is
+func NewApiError(httpStatusCode int, message string) *ApiError {
+	return &ApiError{
+		HttpStatusCode:  httpStatusCode,
+		Code:            strconv.Itoa(httpStatusCode),
+		InternalMessage: message,
+		UserMessage:     message,
+	}
+}
+
+func NewBadRequestError(message string) *ApiError {
+	return NewApiError(http.StatusBadRequest, message)
+}
+
+func NewNotFoundError(message string) *ApiError {
+	return NewApiError(http.StatusNotFound, message)
+}
+
 func IsErrNoRows(err error) bool {
 	return pg.ErrNoRows == err
 }
@@ -0,0 +1,54 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"crypto/subtle"
+	util "github.com/devtron-labs/central-api/client"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+)
+
+type AdminTokenValidator interface {
+	ValidateToken(r *http.Request) bool
+}
+
+type AdminTokenValidatorImpl struct {
+	logger      *zap.SugaredLogger
+	adminConfig *util.AdminConfig
+}
+
+func NewAdminTokenValidatorImpl(logger *zap.SugaredLogger, adminConfig *util.AdminConfig) *AdminTokenValidatorImpl {
+	return &AdminTokenValidatorImpl{
+		logger:      logger,
+		adminConfig: adminConfig,
+	}
+}
+
+// ValidateToken checks the bearer token of the Authorization header against the configured admin token
+func (impl *AdminTokenValidatorImpl) ValidateToken(r *http.Request) bool {
+	if len(impl.adminConfig.AdminApiToken) == 0 {
+		return false
+	}
+	authorization := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(authorization) != 2 || !strings.EqualFold(authorization[0], "Bearer") {
+		return false
+	}
+	token := strings.TrimSpace(authorization[1])
+	return subtle.ConstantTimeCompare([]byte(token), []byte(impl.adminConfig.AdminApiToken)) == 1
+}
@@ -0,0 +1,246 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	internalUtil "github.com/devtron-labs/central-api/internal/util"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AnnouncementService manages the in-product announcements, changes are persisted to ANNOUNCEMENT_FILE
+type AnnouncementService interface {
+	GetAnnouncements() []*common.Announcement
+	GetAnnouncement(id string) (*common.Announcement, error)
+	CreateAnnouncement(request *common.AnnouncementRequest) (*common.Announcement, error)
+	UpdateAnnouncement(id string, request *common.AnnouncementRequest) (*common.Announcement, error)
+	DeleteAnnouncement(id string) error
+	// GetActiveAnnouncements returns the announcements scheduled now which target the installation, newest first
+	GetActiveAnnouncements(installation *common.InstallationContext) ([]*common.Announcement, error)
+}
+
+type AnnouncementServiceImpl struct {
+	logger             *zap.SugaredLogger
+	announcementConfig *util.AnnouncementConfig
+	announcements      *jsonFileStore[*common.Announcement]
+}
+
+func NewAnnouncementServiceImpl(logger *zap.SugaredLogger, announcementConfig *util.AnnouncementConfig) (*AnnouncementServiceImpl, error) {
+	announcements, err := newJsonFileStore(logger, "announcements", announcementConfig.AnnouncementFile,
+		func(announcement *common.Announcement) string {
+			return announcement.Id
+		})
+	if err != nil {
+		return nil, err
+	}
+	serviceImpl := &AnnouncementServiceImpl{
+		logger:             logger,
+		announcementConfig: announcementConfig,
+		announcements:      announcements,
+	}
+	return serviceImpl, nil
+}
+
+func (impl *AnnouncementServiceImpl) GetAnnouncements() []*common.Announcement {
+	announcements := impl.announcements.List()
+	sort.Slice(announcements, func(i, j int) bool {
+		return announcements[i].CreatedOn.Before(announcements[j].CreatedOn)
+	})
+	return announcements
+}
+
+func (impl *AnnouncementServiceImpl) GetAnnouncement(id string) (*common.Announcement, error) {
+	announcement, ok := impl.announcements.Get(id)
+	if !ok {
+		return nil, getAnnouncementNotFoundError(id)
+	}
+	return announcement, nil
+}
+
+func (impl *AnnouncementServiceImpl) CreateAnnouncement(request *common.AnnouncementRequest) (*common.Announcement, error) {
+	err := validateAnnouncementRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	announcement := &common.Announcement{
+		Id:        uuid.New().String(),
+		Active:    request.Active == nil || *request.Active,
+		CreatedOn: now,
+	}
+	applyAnnouncementRequest(announcement, request, now)
+	err = impl.announcements.Add(announcement)
+	if err != nil {
+		return nil, err
+	}
+	return announcement, nil
+}
+
+func (impl *AnnouncementServiceImpl) UpdateAnnouncement(id string, request *common.AnnouncementRequest) (*common.Announcement, error) {
+	err := validateAnnouncementRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	return impl.announcements.Update(id, func(existingAnnouncement *common.Announcement, found bool) (*common.Announcement, error) {
+		if !found {
+			return nil, getAnnouncementNotFoundError(id)
+		}
+		// announcements handed out are never modified, readers may still hold the existing one
+		announcement := *existingAnnouncement
+		if request.Active != nil {
+			announcement.Active = *request.Active
+		}
+		applyAnnouncementRequest(&announcement, request, time.Now())
+		return &announcement, nil
+	})
+}
+
+func (impl *AnnouncementServiceImpl) DeleteAnnouncement(id string) error {
+	found, err := impl.announcements.Delete(id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return getAnnouncementNotFoundError(id)
+	}
+	return nil
+}
+
+func (impl *AnnouncementServiceImpl) GetActiveAnnouncements(installation *common.InstallationContext) ([]*common.Announcement, error) {
+	var version *semver.Version
+	if len(installation.Version) > 0 {
+		var err error
+		version, err = semver.NewVersion(installation.Version)
+		if err != nil {
+			return nil, internalUtil.NewBadRequestError(fmt.Sprintf("invalid version %s", installation.Version))
+		}
+	}
+	now := time.Now()
+	announcements := make([]*common.Announcement, 0)
+	for _, announcement := range impl.GetAnnouncements() {
+		if isAnnouncementScheduled(announcement, now) && isAnnouncementTargeted(announcement, version, installation) {
+			announcements = append(announcements, announcement)
+		}
+	}
+	sort.SliceStable(announcements, func(i, j int) bool {
+		return getAnnouncementTime(announcements[i]).After(getAnnouncementTime(announcements[j]))
+	})
+	return announcements, nil
+}
+
+func isAnnouncementScheduled(announcement *common.Announcement, now time.Time) bool {
+	if !announcement.Active {
+		return false
+	}
+	if announcement.StartsAt != nil && now.Before(*announcement.StartsAt) {
+		return false
+	}
+	return announcement.EndsAt == nil || now.Before(*announcement.EndsAt)
+}
+
+// isAnnouncementTargeted matches the targeting of the announcement, installations not telling their version
+// are not matched by announcements targeting versions
+func isAnnouncementTargeted(announcement *common.Announcement, version *semver.Version, installation *common.InstallationContext) bool {
+	if len(announcement.VersionConstraint) > 0 {
+		versionConstraint, err := semver.NewConstraint(announcement.VersionConstraint)
+		if err != nil || version == nil || !versionConstraint.Check(version) {
+			return false
+		}
+	}
+	if len(announcement.Editions) > 0 && !containsStringFold(announcement.Editions, installation.Edition) {
+		return false
+	}
+	return len(getMissingModule(announcement.Modules, installation.Modules)) == 0
+}
+
+// getAnnouncementTime is the time an announcement is shown from
+func getAnnouncementTime(announcement *common.Announcement) time.Time {
+	if announcement.StartsAt != nil {
+		return *announcement.StartsAt
+	}
+	return announcement.CreatedOn
+}
+
+// getMissingModule returns the first required module which is not installed, empty when all of them are.
+// Announcements and feature flag rules both target installations having all of their modules.
+func getMissingModule(requiredModules []string, installedModules []string) string {
+	for _, module := range requiredModules {
+		if !containsStringFold(installedModules, module) {
+			return module
+		}
+	}
+	return ""
+}
+
+func containsStringFold(values []string, value string) bool {
+	for _, item := range values {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateAnnouncementRequest(request *common.AnnouncementRequest) error {
+	if !bean.IsValidAnnouncementType(request.Type) {
+		return internalUtil.NewBadRequestError(fmt.Sprintf("unknown announcement type %s", request.Type))
+	}
+	if len(strings.TrimSpace(request.Title)) == 0 {
+		return internalUtil.NewBadRequestError("title is required")
+	}
+	if len(request.Link) > 0 {
+		link, err := url.Parse(request.Link)
+		if err != nil || (link.Scheme != "http" && link.Scheme != "https") || len(link.Host) == 0 {
+			return internalUtil.NewBadRequestError(fmt.Sprintf("invalid link %s, an absolute http or https url is required", request.Link))
+		}
+	}
+	if request.StartsAt != nil && request.EndsAt != nil && !request.EndsAt.After(*request.StartsAt) {
+		return internalUtil.NewBadRequestError("endsAt must be after startsAt")
+	}
+	if len(request.VersionConstraint) > 0 {
+		if _, err := semver.NewConstraint(request.VersionConstraint); err != nil {
+			return internalUtil.NewBadRequestError(fmt.Sprintf("invalid versionConstraint %s", request.VersionConstraint))
+		}
+	}
+	return nil
+}
+
+func applyAnnouncementRequest(announcement *common.Announcement, request *common.AnnouncementRequest, now time.Time) {
+	announcement.Type = request.Type
+	announcement.Title = request.Title
+	announcement.Message = request.Message
+	announcement.Link = request.Link
+	announcement.StartsAt = request.StartsAt
+	announcement.EndsAt = request.EndsAt
+	announcement.VersionConstraint = request.VersionConstraint
+	announcement.Editions = request.Editions
+	announcement.Modules = request.Modules
+	announcement.UpdatedOn = now
+}
+
+func getAnnouncementNotFoundError(id string) error {
+	return internalUtil.NewNotFoundError(fmt.Sprintf("announcement %s not found", id))
+}
@@ -19,36 +19,116 @@ package pkg
 import (
 	"encoding/json"
 	"fmt"
+	util "github.com/devtron-labs/central-api/client"
 	"github.com/devtron-labs/central-api/common"
 	"go.uber.org/zap"
 	"os"
+	"sync"
+	"time"
+)
+
+const (
+	dockerfileTemplateDataFile = "/DockerfileTemplateData.json"
+	buildpackMetadataFile      = "/BuildpackMetadata.json"
 )
 
 type CiBuildMetadataService interface {
 	GetDockerfileTemplateMetadata() *common.DockerfileTemplateMetadata
 	GetBuildpackMetadata() *common.BuildPackMetadata
+	AddMetadataReloadListener(listener MetadataReloadListener)
+}
+
+// MetadataReloadListener is notified after metadata files changed and were reloaded
+type MetadataReloadListener interface {
+	OnMetadataReloaded()
 }
 
 type CiBuildMetadataServiceImpl struct {
 	Logger                     *zap.SugaredLogger
 	BuildPackMetadata          *common.BuildPackMetadata
 	DockerfileTemplateMetadata *common.DockerfileTemplateMetadata
+	mutex                      sync.RWMutex
+	fileModTimes               map[string]time.Time
+	reloadListeners            []MetadataReloadListener
 }
 
-func NewCiBuildMetadataServiceImpl(logger *zap.SugaredLogger) *CiBuildMetadataServiceImpl {
+func NewCiBuildMetadataServiceImpl(logger *zap.SugaredLogger, ciBuildMetadataConfig *util.CiBuildMetadataConfig) *CiBuildMetadataServiceImpl {
 	buildpackMetadata := setupBuildpackMetadata()
 	templateMetadata := setupDockerfileTemplateMetadata()
 	metadataServiceImpl := &CiBuildMetadataServiceImpl{
 		Logger:                     logger,
 		BuildPackMetadata:          buildpackMetadata,
 		DockerfileTemplateMetadata: templateMetadata,
+		fileModTimes:               getFileModTimes(dockerfileTemplateDataFile, buildpackMetadataFile),
+	}
+	if ciBuildMetadataConfig.ReloadIntervalInSecs > 0 {
+		go metadataServiceImpl.watchMetadataFiles(time.Duration(ciBuildMetadataConfig.ReloadIntervalInSecs) * time.Second)
 	}
 	return metadataServiceImpl
 }
 
+func (impl *CiBuildMetadataServiceImpl) AddMetadataReloadListener(listener MetadataReloadListener) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	impl.reloadListeners = append(impl.reloadListeners, listener)
+}
+
+// watchMetadataFiles reloads metadata whenever the modification time of a metadata file changes
+func (impl *CiBuildMetadataServiceImpl) watchMetadataFiles(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		fileModTimes := getFileModTimes(dockerfileTemplateDataFile, buildpackMetadataFile)
+		changed := false
+		for fileName, modTime := range fileModTimes {
+			if !modTime.Equal(impl.fileModTimes[fileName]) {
+				changed = true
+			}
+		}
+		if !changed {
+			continue
+		}
+		impl.fileModTimes = fileModTimes
+		impl.reloadMetadata()
+	}
+}
+
+func (impl *CiBuildMetadataServiceImpl) reloadMetadata() {
+	buildpackMetadata := setupBuildpackMetadata()
+	templateMetadata := setupDockerfileTemplateMetadata()
+	impl.mutex.Lock()
+	// files which fail to load keep their previous metadata
+	if buildpackMetadata != nil {
+		impl.BuildPackMetadata = buildpackMetadata
+	}
+	if templateMetadata != nil {
+		impl.DockerfileTemplateMetadata = templateMetadata
+	}
+	listeners := impl.reloadListeners
+	impl.mutex.Unlock()
+	if buildpackMetadata == nil && templateMetadata == nil {
+		impl.Logger.Errorw("error in reloading ci build metadata, keeping previous metadata")
+		return
+	}
+	impl.Logger.Infow("reloaded ci build metadata")
+	for _, listener := range listeners {
+		listener.OnMetadataReloaded()
+	}
+}
+
+func getFileModTimes(fileNames ...string) map[string]time.Time {
+	fileModTimes := make(map[string]time.Time, len(fileNames))
+	for _, fileName := range fileNames {
+		if fileInfo, err := os.Stat(fileName); err == nil {
+			fileModTimes[fileName] = fileInfo.ModTime()
+		}
+	}
+	return fileModTimes
+}
+
 func setupDockerfileTemplateMetadata() *common.DockerfileTemplateMetadata {
 
-	dockerfileTemplateData, err := os.ReadFile("/DockerfileTemplateData.json")
+	dockerfileTemplateData, err := os.ReadFile(dockerfileTemplateDataFile)
 	if err != nil {
 		fmt.Println("error occurred while reading file DockerfileTemplateData.json", "error", err)
 		return nil
@@ -64,7 +144,7 @@ func setupDockerfileTemplateMetadata() *common.DockerfileTemplateMetadata {
 
 func setupBuildpackMetadata() *common.BuildPackMetadata {
 
-	buildpackMetadataBytes, err := os.ReadFile("/BuildpackMetadata.json")
+	buildpackMetadataBytes, err := os.ReadFile(buildpackMetadataFile)
 	if err != nil {
 		fmt.Println("error occurred while reading file DockerfileTemplateData.json", "error", err)
 		return nil
@@ -78,10 +158,14 @@ func setupBuildpackMetadata() *common.BuildPackMetadata {
 	return buildpackMetadata
 }
 
-func (impl CiBuildMetadataServiceImpl) GetDockerfileTemplateMetadata() *common.DockerfileTemplateMetadata {
+func (impl *CiBuildMetadataServiceImpl) GetDockerfileTemplateMetadata() *common.DockerfileTemplateMetadata {
+	impl.mutex.RLock()
+	defer impl.mutex.RUnlock()
 	return impl.DockerfileTemplateMetadata
 }
 
-func (impl CiBuildMetadataServiceImpl) GetBuildpackMetadata() *common.BuildPackMetadata {
+func (impl *CiBuildMetadataServiceImpl) GetBuildpackMetadata() *common.BuildPackMetadata {
+	impl.mutex.RLock()
+	defer impl.mutex.RUnlock()
 	return impl.BuildPackMetadata
 }
@@ -0,0 +1,199 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"encoding/json"
+	"fmt"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	internalUtil "github.com/devtron-labs/central-api/internal/util"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type EventStreamService interface {
+	// Subscribe registers a subscriber for the given event types, all types when empty. Events after lastEventId
+	// still held in the replay buffer are returned to be sent before the ones received on the subscription.
+	Subscribe(eventTypes []string, lastEventId string) (*EventSubscription, []*common.StreamEvent, error)
+	Unsubscribe(subscription *EventSubscription)
+	Publish(eventType string, data interface{})
+}
+
+// EventSubscription receives published events on Events, which is closed when the subscriber is
+// unsubscribed or disconnected for lagging behind
+type EventSubscription struct {
+	Events     chan *common.StreamEvent
+	eventTypes map[string]bool
+}
+
+func (subscription *EventSubscription) accepts(eventType string) bool {
+	return len(subscription.eventTypes) == 0 || subscription.eventTypes[eventType]
+}
+
+type EventStreamServiceImpl struct {
+	logger            *zap.SugaredLogger
+	eventStreamConfig *util.EventStreamConfig
+	mutex             sync.Mutex
+	lastEventId       uint64
+	// replayBuffer holds the latest events oldest first, bounded by ReplayBufferSize
+	replayBuffer  []*common.StreamEvent
+	subscriptions map[*EventSubscription]bool
+	// catalog updates are published only once the releases cached before start are replayed
+	ready bool
+}
+
+func NewEventStreamServiceImpl(logger *zap.SugaredLogger, eventStreamConfig *util.EventStreamConfig, releaseNoteService ReleaseNoteService,
+	ciBuildMetadataService CiBuildMetadataService) *EventStreamServiceImpl {
+	serviceImpl := &EventStreamServiceImpl{
+		logger:            logger,
+		eventStreamConfig: eventStreamConfig,
+		replayBuffer:      make([]*common.StreamEvent, 0, eventStreamConfig.ReplayBufferSize),
+		subscriptions:     make(map[*EventSubscription]bool),
+	}
+	releaseNoteService.AddReleaseEventListener(serviceImpl)
+	releaseNoteService.AddReleaseCacheListener(serviceImpl)
+	ciBuildMetadataService.AddMetadataReloadListener(serviceImpl)
+	serviceImpl.mutex.Lock()
+	serviceImpl.ready = true
+	serviceImpl.mutex.Unlock()
+	return serviceImpl
+}
+
+func (impl *EventStreamServiceImpl) Subscribe(eventTypes []string, lastEventId string) (*EventSubscription, []*common.StreamEvent, error) {
+	subscription := &EventSubscription{
+		Events:     make(chan *common.StreamEvent, impl.eventStreamConfig.SubscriberBufferSize),
+		eventTypes: make(map[string]bool, len(eventTypes)),
+	}
+	for _, eventType := range eventTypes {
+		if !bean.IsValidStreamEventType(eventType) {
+			return nil, nil, internalUtil.NewBadRequestError(fmt.Sprintf("invalid event type %s", eventType))
+		}
+		subscription.eventTypes[eventType] = true
+	}
+	var lastId uint64
+	resume := len(lastEventId) > 0
+	if resume {
+		var err error
+		lastId, err = strconv.ParseUint(lastEventId, 10, 64)
+		if err != nil {
+			return nil, nil, internalUtil.NewBadRequestError(fmt.Sprintf("invalid last event id %s", lastEventId))
+		}
+	}
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	if len(impl.subscriptions) >= impl.eventStreamConfig.MaxSubscribers {
+		return nil, nil, &internalUtil.ApiError{
+			HttpStatusCode:  http.StatusServiceUnavailable,
+			Code:            "503",
+			InternalMessage: "event stream subscriber limit reached",
+			UserMessage:     "too many event stream subscribers, retry later",
+		}
+	}
+	replayEvents := make([]*common.StreamEvent, 0)
+	if resume {
+		if lastId > impl.lastEventId {
+			// ids restart with the server, everything still buffered is newer than what the client has seen
+			lastId = 0
+		}
+		for _, event := range impl.replayBuffer {
+			if event.Id > lastId && subscription.accepts(event.Type) {
+				replayEvents = append(replayEvents, event)
+			}
+		}
+	}
+	impl.subscriptions[subscription] = true
+	return subscription, replayEvents, nil
+}
+
+func (impl *EventStreamServiceImpl) Unsubscribe(subscription *EventSubscription) {
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	if impl.subscriptions[subscription] {
+		delete(impl.subscriptions, subscription)
+		close(subscription.Events)
+	}
+}
+
+func (impl *EventStreamServiceImpl) Publish(eventType string, data interface{}) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		impl.logger.Errorw("error in marshalling stream event", "eventType", eventType, "err", err)
+		return
+	}
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	impl.lastEventId++
+	event := &common.StreamEvent{
+		Id:   impl.lastEventId,
+		Type: eventType,
+		Data: payload,
+	}
+	if impl.eventStreamConfig.ReplayBufferSize > 0 {
+		if len(impl.replayBuffer) >= impl.eventStreamConfig.ReplayBufferSize {
+			impl.replayBuffer = append(impl.replayBuffer[:0], impl.replayBuffer[1:]...)
+		}
+		impl.replayBuffer = append(impl.replayBuffer, event)
+	}
+	for subscription := range impl.subscriptions {
+		if !subscription.accepts(eventType) {
+			continue
+		}
+		select {
+		case subscription.Events <- event:
+		default:
+			// a lagging subscriber is disconnected and catches up through Last-Event-ID on reconnect
+			impl.logger.Warnw("disconnecting lagging event stream subscriber", "eventId", event.Id)
+			delete(impl.subscriptions, subscription)
+			close(subscription.Events)
+		}
+	}
+}
+
+func (impl *EventStreamServiceImpl) OnReleaseEvent(event *common.ReleaseEvent) {
+	impl.Publish(event.Type, event)
+}
+
+func (impl *EventStreamServiceImpl) OnReleaseCacheUpdated(repository bean.Repository, releases []*common.Release) {
+	impl.mutex.Lock()
+	ready := impl.ready
+	impl.mutex.Unlock()
+	if !ready {
+		return
+	}
+	catalogUpdate := &common.CatalogUpdate{
+		Repository:   string(repository),
+		ReleaseCount: len(releases),
+	}
+	// the latest tag is replaced by the latest release visible to a subscriber when it is still rolling out
+	sortedReleases, _ := common.SortReleasesBySemver(releases)
+	for _, release := range sortedReleases {
+		if !release.Draft && !release.Prerelease {
+			catalogUpdate.LatestTag = release.TagName
+			break
+		}
+	}
+	impl.Publish(bean.CatalogUpdatedEvent, catalogUpdate)
+}
+
+func (impl *EventStreamServiceImpl) OnMetadataReloaded() {
+	impl.Publish(bean.MetadataReloadedEvent, &common.MetadataReload{ReloadedOn: time.Now()})
+}
@@ -0,0 +1,302 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	internalUtil "github.com/devtron-labs/central-api/internal/util"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FeatureFlagService evaluates the feature flags and remote config of FEATURE_FLAG_CATALOG_FILE for installations
+type FeatureFlagService interface {
+	// EvaluateFlags returns the value of every flag for the installation, keys restricts the flags evaluated
+	// and explain adds how each value was chosen
+	EvaluateFlags(installation *common.InstallationContext, keys []string, explain bool) (*common.FeatureFlagEvaluation, error)
+}
+
+// featureFlagCatalog is the catalog file, version is bumped by the catalog authors on every change
+type featureFlagCatalog struct {
+	Version string         `yaml:"version"`
+	Flags   []*featureFlag `yaml:"flags"`
+}
+
+// featureFlag serves the value of the first matching rule, default when none matches or the flag is disabled
+type featureFlag struct {
+	Key         string             `yaml:"key"`
+	Description string             `yaml:"description"`
+	Disabled    bool               `yaml:"disabled"`
+	Default     interface{}        `yaml:"default"`
+	Rules       []*featureFlagRule `yaml:"rules"`
+}
+
+// featureFlagRule matches installations meeting all of its conditions, empty conditions match everything.
+// Editions match any of them while all Modules have to be installed, the same as for announcements.
+// Rollout is the percentage of installations matched, bucketed by their hashed installation id.
+type featureFlagRule struct {
+	Name              string      `yaml:"name"`
+	VersionConstraint string      `yaml:"versionConstraint"`
+	Editions          []string    `yaml:"editions"`
+	Modules           []string    `yaml:"modules"`
+	Rollout           *int        `yaml:"rollout"`
+	Value             interface{} `yaml:"value"`
+
+	versionConstraint *semver.Constraints
+}
+
+type FeatureFlagServiceImpl struct {
+	logger            *zap.SugaredLogger
+	featureFlagConfig *util.FeatureFlagConfig
+	mutex             sync.RWMutex
+	catalog           *featureFlagCatalog
+	catalogModTime    time.Time
+}
+
+func NewFeatureFlagServiceImpl(logger *zap.SugaredLogger, featureFlagConfig *util.FeatureFlagConfig) (*FeatureFlagServiceImpl, error) {
+	serviceImpl := &FeatureFlagServiceImpl{
+		logger:            logger,
+		featureFlagConfig: featureFlagConfig,
+		catalog:           &featureFlagCatalog{},
+	}
+	catalogFile := featureFlagConfig.CatalogFile
+	if len(catalogFile) == 0 {
+		logger.Warnw("feature flag catalog file is not configured, no flags are served")
+		return serviceImpl, nil
+	}
+	serviceImpl.catalogModTime = getFileModTimes(catalogFile)[catalogFile]
+	catalog, err := loadFeatureFlagCatalog(catalogFile)
+	if err != nil {
+		logger.Errorw("error in loading feature flag catalog", "file", catalogFile, "err", err)
+		return nil, err
+	}
+	serviceImpl.catalog = catalog
+	if featureFlagConfig.ReloadIntervalInSecs > 0 {
+		go serviceImpl.watchCatalogFile(time.Duration(featureFlagConfig.ReloadIntervalInSecs) * time.Second)
+	}
+	return serviceImpl, nil
+}
+
+// watchCatalogFile reloads the catalog whenever its modification time changes, an invalid catalog keeps the previous one
+func (impl *FeatureFlagServiceImpl) watchCatalogFile(interval time.Duration) {
+	catalogFile := impl.featureFlagConfig.CatalogFile
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		modTime := getFileModTimes(catalogFile)[catalogFile]
+		if modTime.Equal(impl.catalogModTime) {
+			continue
+		}
+		impl.catalogModTime = modTime
+		catalog, err := loadFeatureFlagCatalog(catalogFile)
+		if err != nil {
+			impl.logger.Errorw("error in reloading feature flag catalog, keeping previous catalog", "file", catalogFile, "err", err)
+			continue
+		}
+		impl.mutex.Lock()
+		impl.catalog = catalog
+		impl.mutex.Unlock()
+		impl.logger.Infow("reloaded feature flag catalog", "version", catalog.Version)
+	}
+}
+
+func loadFeatureFlagCatalog(catalogFile string) (*featureFlagCatalog, error) {
+	content, err := ioutil.ReadFile(catalogFile)
+	if err != nil {
+		return nil, err
+	}
+	catalog := &featureFlagCatalog{}
+	err = yaml.UnmarshalStrict(content, catalog)
+	if err != nil {
+		return nil, err
+	}
+	if len(catalog.Version) == 0 {
+		return nil, fmt.Errorf("catalog version is required")
+	}
+	keys := make(map[string]bool)
+	for i, flag := range catalog.Flags {
+		if flag == nil || len(flag.Key) == 0 {
+			return nil, fmt.Errorf("flag %d has no key", i+1)
+		}
+		if keys[flag.Key] {
+			return nil, fmt.Errorf("duplicate flag %s", flag.Key)
+		}
+		keys[flag.Key] = true
+		flag.Default = toJsonValue(flag.Default)
+		for j, rule := range flag.Rules {
+			if rule == nil {
+				return nil, fmt.Errorf("rule %d of flag %s is empty", j+1, flag.Key)
+			}
+			if len(rule.Name) == 0 {
+				rule.Name = fmt.Sprintf("rule-%d", j+1)
+			}
+			if len(rule.VersionConstraint) > 0 {
+				rule.versionConstraint, err = semver.NewConstraint(rule.VersionConstraint)
+				if err != nil {
+					return nil, fmt.Errorf("rule %s of flag %s has invalid versionConstraint %s", rule.Name, flag.Key, rule.VersionConstraint)
+				}
+			}
+			if rule.Rollout != nil && (*rule.Rollout < 0 || *rule.Rollout > 100) {
+				return nil, fmt.Errorf("rule %s of flag %s has rollout %d out of 0-100", rule.Name, flag.Key, *rule.Rollout)
+			}
+			rule.Value = toJsonValue(rule.Value)
+		}
+	}
+	return catalog, nil
+}
+
+// toJsonValue converts the maps yaml decodes config values into, which json can not encode
+func toJsonValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[interface{}]interface{}:
+		jsonMap := make(map[string]interface{}, len(typedValue))
+		for key, item := range typedValue {
+			jsonMap[fmt.Sprint(key)] = toJsonValue(item)
+		}
+		return jsonMap
+	case []interface{}:
+		jsonList := make([]interface{}, len(typedValue))
+		for i, item := range typedValue {
+			jsonList[i] = toJsonValue(item)
+		}
+		return jsonList
+	}
+	return value
+}
+
+func (impl *FeatureFlagServiceImpl) EvaluateFlags(installation *common.InstallationContext, keys []string, explain bool) (*common.FeatureFlagEvaluation, error) {
+	var version *semver.Version
+	if len(installation.Version) > 0 {
+		var err error
+		version, err = semver.NewVersion(installation.Version)
+		if err != nil {
+			message := fmt.Sprintf("invalid version %s", installation.Version)
+			return nil, internalUtil.NewBadRequestError(message)
+		}
+	}
+	impl.mutex.RLock()
+	catalog := impl.catalog
+	impl.mutex.RUnlock()
+	evaluation := &common.FeatureFlagEvaluation{
+		CatalogVersion: catalog.Version,
+		Flags:          make(map[string]interface{}),
+	}
+	if explain {
+		evaluation.Explanations = make([]*common.FeatureFlagExplanation, 0)
+	}
+	flagsByKey := make(map[string]*featureFlag, len(catalog.Flags))
+	for _, flag := range catalog.Flags {
+		flagsByKey[flag.Key] = flag
+	}
+	if len(keys) == 0 {
+		for _, flag := range catalog.Flags {
+			keys = append(keys, flag.Key)
+		}
+	}
+	for _, key := range keys {
+		flag, ok := flagsByKey[key]
+		if !ok {
+			if explain {
+				evaluation.Explanations = append(evaluation.Explanations, &common.FeatureFlagExplanation{
+					Key:    key,
+					Reason: bean.FeatureFlagReasonNotFound,
+					Steps:  []string{fmt.Sprintf("flag %s is not in catalog version %s", key, catalog.Version)},
+				})
+			}
+			continue
+		}
+		explanation := evaluateFlag(flag, version, installation)
+		evaluation.Flags[key] = explanation.Value
+		if explain {
+			evaluation.Explanations = append(evaluation.Explanations, explanation)
+		}
+	}
+	return evaluation, nil
+}
+
+func evaluateFlag(flag *featureFlag, version *semver.Version, installation *common.InstallationContext) *common.FeatureFlagExplanation {
+	explanation := &common.FeatureFlagExplanation{
+		Key:   flag.Key,
+		Steps: make([]string, 0),
+	}
+	if flag.Disabled {
+		explanation.Value = flag.Default
+		explanation.Reason = bean.FeatureFlagReasonDisabled
+		explanation.Steps = append(explanation.Steps, "flag is disabled, serving default")
+		return explanation
+	}
+	for _, rule := range flag.Rules {
+		mismatch := getRuleMismatch(flag.Key, rule, version, installation)
+		if len(mismatch) > 0 {
+			explanation.Steps = append(explanation.Steps, fmt.Sprintf("rule %s did not match: %s", rule.Name, mismatch))
+			continue
+		}
+		explanation.Steps = append(explanation.Steps, fmt.Sprintf("rule %s matched", rule.Name))
+		explanation.Value = rule.Value
+		explanation.Reason = bean.FeatureFlagReasonRuleMatched
+		explanation.Rule = rule.Name
+		return explanation
+	}
+	explanation.Value = flag.Default
+	explanation.Reason = bean.FeatureFlagReasonDefault
+	return explanation
+}
+
+// getRuleMismatch returns why the rule does not match the installation, empty when it matches
+func getRuleMismatch(flagKey string, rule *featureFlagRule, version *semver.Version, installation *common.InstallationContext) string {
+	if rule.versionConstraint != nil {
+		if version == nil {
+			return "installation version is unknown"
+		}
+		if !rule.versionConstraint.Check(version) {
+			return fmt.Sprintf("version %s does not satisfy %s", installation.Version, rule.VersionConstraint)
+		}
+	}
+	if len(rule.Editions) > 0 && !containsStringFold(rule.Editions, installation.Edition) {
+		return fmt.Sprintf("edition %q is not one of %s", installation.Edition, strings.Join(rule.Editions, ", "))
+	}
+	if module := getMissingModule(rule.Modules, installation.Modules); len(module) > 0 {
+		return fmt.Sprintf("module %s is not installed", module)
+	}
+	if rule.Rollout != nil && *rule.Rollout < 100 {
+		if len(installation.InstallationId) == 0 {
+			return "installation id is required for rollout"
+		}
+		bucket := getRolloutBucket(flagKey, installation.InstallationId)
+		if bucket >= *rule.Rollout {
+			return fmt.Sprintf("rollout bucket %d is not below %d%%", bucket, *rule.Rollout)
+		}
+	}
+	return ""
+}
+
+// getRolloutBucket places an installation in one of 100 buckets, stable for the same salt so that a
+// rollout only grows to new installations when its percentage is raised
+func getRolloutBucket(salt string, installationId string) int {
+	hash := sha256.Sum256([]byte(salt + ":" + installationId))
+	return int(binary.BigEndian.Uint32(hash[:4]) % 100)
+}
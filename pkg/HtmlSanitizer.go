@@ -0,0 +1,272 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"bytes"
+	"golang.org/x/net/html"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// sanitizerAllowedAttributes is the allow-list of html elements and their attributes which survive sanitization
+var sanitizerAllowedAttributes = map[string][]string{
+	"a":          {"href", "title"},
+	"abbr":       {"title"},
+	"b":          {},
+	"blockquote": {},
+	"br":         {},
+	"code":       {"class"},
+	"dd":         {},
+	"del":        {},
+	"details":    {"open"},
+	"div":        {},
+	"dl":         {},
+	"dt":         {},
+	"em":         {},
+	"h1":         {"id"},
+	"h2":         {"id"},
+	"h3":         {"id"},
+	"h4":         {"id"},
+	"h5":         {"id"},
+	"h6":         {"id"},
+	"hr":         {},
+	"i":          {},
+	"img":        {"src", "alt", "title", "width", "height"},
+	"input":      {"type", "checked", "disabled"},
+	"ins":        {},
+	"kbd":        {},
+	"li":         {},
+	"ol":         {"start"},
+	"p":          {},
+	"pre":        {},
+	"q":          {},
+	"s":          {},
+	"samp":       {},
+	"span":       {},
+	"strong":     {},
+	"sub":        {},
+	"summary":    {},
+	"sup":        {},
+	"table":      {},
+	"tbody":      {},
+	"td":         {"align", "colspan", "rowspan"},
+	"tfoot":      {},
+	"th":         {"align", "colspan", "rowspan"},
+	"thead":      {},
+	"tr":         {},
+	"ul":         {},
+}
+
+// sanitizerDroppedElements are removed along with everything they contain
+var sanitizerDroppedElements = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"frame":    true,
+	"frameset": true,
+	"object":   true,
+	"embed":    true,
+	"noscript": true,
+	"template": true,
+	"textarea": true,
+	"title":    true,
+	"svg":      true,
+	"math":     true,
+}
+
+var sanitizerAllowedUrlSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+var sanitizerIdRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+var sanitizerCodeClassRegex = regexp.MustCompile(`^language-[A-Za-z0-9_+-]+$`)
+var sanitizerNumberRegex = regexp.MustCompile(`^[0-9]{1,4}%?$`)
+
+// sanitizeHtml keeps only allow-listed elements and attributes of the given html. Disallowed elements are
+// unwrapped, their text content is kept escaped, except for sanitizerDroppedElements which are removed entirely.
+func sanitizeHtml(content string) string {
+	var buf bytes.Buffer
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	droppedDepth := 0
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			// io.EOF or an unparsable remainder, which is dropped
+			break
+		}
+		token := tokenizer.Token()
+		switch tokenType {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if sanitizerDroppedElements[token.Data] {
+				if tokenType == html.StartTagToken {
+					droppedDepth++
+				}
+				continue
+			}
+			if droppedDepth > 0 {
+				continue
+			}
+			allowedAttributes, ok := sanitizerAllowedAttributes[token.Data]
+			if !ok {
+				continue
+			}
+			if token.Data == "input" && !isCheckboxInput(token) {
+				continue
+			}
+			buf.WriteString("<")
+			buf.WriteString(token.Data)
+			for _, attribute := range token.Attr {
+				if len(attribute.Namespace) > 0 || !containsString(allowedAttributes, attribute.Key) {
+					continue
+				}
+				value, ok := sanitizeAttributeValue(token.Data, attribute.Key, attribute.Val)
+				if !ok {
+					continue
+				}
+				buf.WriteString(" ")
+				buf.WriteString(attribute.Key)
+				buf.WriteString(`="`)
+				buf.WriteString(html.EscapeString(value))
+				buf.WriteString(`"`)
+			}
+			if token.Data == "a" {
+				buf.WriteString(` rel="nofollow noopener noreferrer"`)
+			}
+			if tokenType == html.SelfClosingTagToken {
+				buf.WriteString(" /")
+			}
+			buf.WriteString(">")
+		case html.EndTagToken:
+			if sanitizerDroppedElements[token.Data] {
+				if droppedDepth > 0 {
+					droppedDepth--
+				}
+				continue
+			}
+			if droppedDepth > 0 {
+				continue
+			}
+			if _, ok := sanitizerAllowedAttributes[token.Data]; !ok {
+				continue
+			}
+			buf.WriteString("</")
+			buf.WriteString(token.Data)
+			buf.WriteString(">")
+		case html.TextToken:
+			if droppedDepth > 0 {
+				continue
+			}
+			buf.WriteString(html.EscapeString(token.Data))
+		}
+		// comments and doctype are dropped
+	}
+	return buf.String()
+}
+
+// htmlToText returns the text content of sanitized html, block elements are separated by new lines
+func htmlToText(content string) string {
+	var buf bytes.Buffer
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		token := tokenizer.Token()
+		switch tokenType {
+		case html.TextToken:
+			buf.WriteString(token.Data)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch token.Data {
+			case "br":
+				buf.WriteString("\n")
+			case "li":
+				buf.WriteString("- ")
+			}
+		case html.EndTagToken:
+			switch token.Data {
+			case "p", "h1", "h2", "h3", "h4", "h5", "h6", "li", "pre", "tr", "div", "blockquote", "summary", "details", "table", "ul", "ol":
+				buf.WriteString("\n")
+			case "td", "th":
+				buf.WriteString("\t")
+			}
+		}
+	}
+	text := multipleNewLineRegex.ReplaceAllString(buf.String(), "\n\n")
+	return strings.TrimSpace(text)
+}
+
+var multipleNewLineRegex = regexp.MustCompile(`\n{3,}`)
+
+func sanitizeAttributeValue(element string, key string, value string) (string, bool) {
+	switch key {
+	case "href", "src":
+		return sanitizeUrl(value)
+	case "id":
+		return value, sanitizerIdRegex.MatchString(value)
+	case "class":
+		return value, element == "code" && sanitizerCodeClassRegex.MatchString(value)
+	case "width", "height", "colspan", "rowspan", "start":
+		return value, sanitizerNumberRegex.MatchString(value)
+	case "align":
+		return value, value == "left" || value == "right" || value == "center"
+	case "type":
+		return value, value == "checkbox"
+	}
+	return value, true
+}
+
+// sanitizeUrl allows relative urls and absolute urls with an allow-listed scheme
+func sanitizeUrl(value string) (string, bool) {
+	value = strings.TrimSpace(value)
+	for _, r := range value {
+		if r < 0x20 || r == 0x7f {
+			return "", false
+		}
+	}
+	parsedUrl, err := url.Parse(value)
+	if err != nil {
+		return "", false
+	}
+	if len(parsedUrl.Scheme) == 0 {
+		// scheme-less urls are kept only when they can not be read as a scheme by browsers
+		return value, !strings.Contains(strings.SplitN(value, "/", 2)[0], ":")
+	}
+	return value, sanitizerAllowedUrlSchemes[strings.ToLower(parsedUrl.Scheme)]
+}
+
+func isCheckboxInput(token html.Token) bool {
+	for _, attribute := range token.Attr {
+		if attribute.Key == "type" && attribute.Val == "checkbox" {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"encoding/json"
+	"go.uber.org/zap"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// jsonFileStore holds items keyed by id in memory and persists all of them as a json list to a file, without a
+// file the items are kept in memory only. Changes which can not be persisted are rolled back. Items handed out
+// are shared with readers and are never modified, updates replace them.
+type jsonFileStore[T any] struct {
+	logger *zap.SugaredLogger
+	// name of the items used in logs
+	name  string
+	file  string
+	getId func(item T) string
+	mutex sync.RWMutex
+	items map[string]T
+}
+
+func newJsonFileStore[T any](logger *zap.SugaredLogger, name string, file string, getId func(item T) string) (*jsonFileStore[T], error) {
+	store := &jsonFileStore[T]{
+		logger: logger,
+		name:   name,
+		file:   file,
+		getId:  getId,
+		items:  make(map[string]T),
+	}
+	err := store.load()
+	if err != nil {
+		logger.Errorw("error in loading "+name, "file", file, "err", err)
+		return nil, err
+	}
+	return store, nil
+}
+
+func (store *jsonFileStore[T]) Get(id string) (T, bool) {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	item, ok := store.items[id]
+	return item, ok
+}
+
+// List returns the items in no particular order
+func (store *jsonFileStore[T]) List() []T {
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	items := make([]T, 0, len(store.items))
+	for _, item := range store.items {
+		items = append(items, item)
+	}
+	return items
+}
+
+// Update stores the item returned by update as the item of id. update is called under the store lock with the
+// current item of id if there is one, nothing is changed when it returns an error.
+func (store *jsonFileStore[T]) Update(id string, update func(item T, found bool) (T, error)) (T, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	existingItem, found := store.items[id]
+	item, err := update(existingItem, found)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	store.items[id] = item
+	err = store.save()
+	if err != nil {
+		if found {
+			store.items[id] = existingItem
+		} else {
+			delete(store.items, id)
+		}
+		var zero T
+		return zero, err
+	}
+	return item, nil
+}
+
+// Add stores a new item
+func (store *jsonFileStore[T]) Add(item T) error {
+	_, err := store.Update(store.getId(item), func(T, bool) (T, error) {
+		return item, nil
+	})
+	return err
+}
+
+// Delete removes the item of id, false is returned when there is no such item
+func (store *jsonFileStore[T]) Delete(id string) (bool, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	item, found := store.items[id]
+	if !found {
+		return false, nil
+	}
+	delete(store.items, id)
+	err := store.save()
+	if err != nil {
+		store.items[id] = item
+		return false, err
+	}
+	return true, nil
+}
+
+func (store *jsonFileStore[T]) load() error {
+	if len(store.file) == 0 {
+		store.logger.Warnw(store.name + " file is not configured, " + store.name + " are kept in memory only")
+		return nil
+	}
+	content, err := ioutil.ReadFile(store.file)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	var items []T
+	err = json.Unmarshal(content, &items)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		store.items[store.getId(item)] = item
+	}
+	return nil
+}
+
+// save writes all items to the file, callers hold the write lock
+func (store *jsonFileStore[T]) save() error {
+	if len(store.file) == 0 {
+		return nil
+	}
+	items := make([]T, 0, len(store.items))
+	for _, item := range store.items {
+		items = append(items, item)
+	}
+	content, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomically(store.file, content)
+	if err != nil {
+		store.logger.Errorw("error in saving "+store.name, "file", store.file, "err", err)
+	}
+	return err
+}
+
+// writeFileAtomically replaces the file with content through a rename so that readers never see a partial file,
+// the file may hold secrets and is only readable by the owner
+func writeFileAtomically(fileName string, content []byte) error {
+	tempFile, err := ioutil.TempFile(filepath.Dir(fileName), filepath.Base(fileName)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tempFile.Name())
+	if _, err = tempFile.Write(content); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err = tempFile.Chmod(0600); err != nil {
+		tempFile.Close()
+		return err
+	}
+	if err = tempFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tempFile.Name(), fileName)
+}
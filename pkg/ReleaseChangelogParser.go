@@ -0,0 +1,167 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"github.com/devtron-labs/central-api/common"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"regexp"
+	"strings"
+)
+
+var changelogHeadingRegex = regexp.MustCompile(`^#{1,6}\s+(.+?)\s*#*\s*$`)
+var changelogListItemRegex = regexp.MustCompile(`^([-*+]|[0-9]+[.)])\s+(.+)$`)
+
+// changelogSectionKeywords maps lower cased heading keywords to section types, first match wins
+var changelogSectionKeywords = []struct {
+	keyword     string
+	sectionType common.ReleaseSectionType
+}{
+	{"feature", common.ReleaseSectionFeatures},
+	{"enhancement", common.ReleaseSectionEnhancements},
+	{"improvement", common.ReleaseSectionEnhancements},
+	{"bug", common.ReleaseSectionBugFixes},
+	{"fix", common.ReleaseSectionBugFixes},
+	{"doc", common.ReleaseSectionDocumentation},
+}
+
+// changelogSkippedBlockMatchers enclose blocks of a release body which are served on their own and are not
+// part of the changelog
+var changelogSkippedBlockMatchers = []string{bean.PrerequisitesMatcher, bean.BreakingChangesMatcher, bean.DeprecationsMatcher}
+
+// parseReleaseSections splits a release body on markdown headings following the devtron release convention
+// ("Features", "Bug Fixes", "Enhancements" ...) and collects the top level list items of every section.
+// Content inside fenced code blocks, the release metadata comment and the blocks of changelogSkippedBlockMatchers
+// is ignored, headings without items are dropped.
+func parseReleaseSections(body string) []*common.ReleaseSection {
+	sections := make([]*common.ReleaseSection, 0)
+	var currentSection *common.ReleaseSection
+	var currentItem *common.ChangelogItem
+	inFence, inMetadata := false, false
+	inSkippedBlock := make(map[string]bool, len(changelogSkippedBlockMatchers))
+	for _, line := range strings.Split(body, "\n") {
+		trimmedLine := strings.TrimSpace(line)
+		if inMetadata {
+			inMetadata = !strings.Contains(trimmedLine, bean.ReleaseMetadataEndMatcher)
+			continue
+		}
+		if metadataStart := strings.Index(trimmedLine, bean.ReleaseMetadataMatcher); metadataStart >= 0 {
+			inMetadata = !strings.Contains(trimmedLine[metadataStart:], bean.ReleaseMetadataEndMatcher)
+			currentItem = nil
+			continue
+		}
+		isMarkerLine := false
+		for _, matcher := range changelogSkippedBlockMatchers {
+			if strings.Count(trimmedLine, matcher)%2 == 1 {
+				inSkippedBlock[matcher] = !inSkippedBlock[matcher]
+				isMarkerLine = true
+			}
+		}
+		if isMarkerLine {
+			currentItem = nil
+			continue
+		}
+		if strings.HasPrefix(trimmedLine, "```") {
+			inFence = !inFence
+			currentItem = nil
+			continue
+		}
+		if inFence || isInSkippedBlock(inSkippedBlock) {
+			continue
+		}
+		if heading := changelogHeadingRegex.FindStringSubmatch(trimmedLine); heading != nil {
+			currentSection = &common.ReleaseSection{
+				Type:  getReleaseSectionType(heading[1]),
+				Title: heading[1],
+				Items: make([]*common.ChangelogItem, 0),
+			}
+			sections = append(sections, currentSection)
+			currentItem = nil
+			continue
+		}
+		if currentSection == nil || len(trimmedLine) == 0 {
+			currentItem = nil
+			continue
+		}
+		isTopLevel := len(line) == len(strings.TrimLeft(line, " \t"))
+		if listItem := changelogListItemRegex.FindStringSubmatch(trimmedLine); listItem != nil && isTopLevel {
+			currentItem = &common.ChangelogItem{Text: listItem[2]}
+			currentSection.Items = append(currentSection.Items, currentItem)
+		} else if currentItem != nil {
+			// nested items and wrapped lines belong to the current item
+			currentItem.Text = currentItem.Text + "\n" + trimmedLine
+		}
+	}
+	nonEmptySections := make([]*common.ReleaseSection, 0, len(sections))
+	for _, section := range sections {
+		if len(section.Items) > 0 {
+			nonEmptySections = append(nonEmptySections, section)
+		}
+	}
+	return nonEmptySections
+}
+
+func isInSkippedBlock(inSkippedBlock map[string]bool) bool {
+	for _, inBlock := range inSkippedBlock {
+		if inBlock {
+			return true
+		}
+	}
+	return false
+}
+
+func getReleaseSectionType(heading string) common.ReleaseSectionType {
+	heading = strings.ToLower(heading)
+	for _, sectionKeyword := range changelogSectionKeywords {
+		if strings.Contains(heading, sectionKeyword.keyword) {
+			return sectionKeyword.sectionType
+		}
+	}
+	return common.ReleaseSectionOthers
+}
+
+// mergeReleaseSections merges the sections of the given releases by section type, items keep the
+// order of the releases and carry the tag they were released in
+func mergeReleaseSections(releases []*common.Release) []*common.ReleaseSection {
+	sectionsByType := make(map[common.ReleaseSectionType]*common.ReleaseSection)
+	for _, release := range releases {
+		for _, section := range release.Sections {
+			mergedSection, ok := sectionsByType[section.Type]
+			if !ok {
+				mergedSection = &common.ReleaseSection{
+					Type:  section.Type,
+					Title: section.Title,
+					Items: make([]*common.ChangelogItem, 0),
+				}
+				sectionsByType[section.Type] = mergedSection
+			}
+			for _, item := range section.Items {
+				mergedSection.Items = append(mergedSection.Items, &common.ChangelogItem{
+					Text:    item.Text,
+					TagName: release.TagName,
+				})
+			}
+		}
+	}
+	mergedSections := make([]*common.ReleaseSection, 0, len(sectionsByType))
+	for _, sectionType := range common.ReleaseSectionOrder {
+		if section, ok := sectionsByType[sectionType]; ok {
+			mergedSections = append(mergedSections, section)
+		}
+	}
+	return mergedSections
+}
@@ -0,0 +1,154 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"go.uber.org/zap"
+	"regexp"
+)
+
+// ReleaseChannelService matches releases to channels, repositories configured with own channels use those
+// instead of RELEASE_CHANNELS
+type ReleaseChannelService interface {
+	GetChannelNames(repository bean.Repository) []string
+	IsValidChannel(repository bean.Repository, name string) bool
+	GetChannelsForRelease(repository bean.Repository, release *common.Release) []string
+}
+
+type releaseChannelMatcher struct {
+	name              string
+	tagPattern        *regexp.Regexp
+	versionConstraint *semver.Constraints
+	prerelease        *bool
+}
+
+type ReleaseChannelServiceImpl struct {
+	logger               *zap.SugaredLogger
+	channels             []*releaseChannelMatcher
+	channelsByRepository map[bean.Repository][]*releaseChannelMatcher
+}
+
+func NewReleaseChannelServiceImpl(logger *zap.SugaredLogger, releaseChannelConfig *util.ReleaseChannelConfig,
+	client *util.GitHubClient) (*ReleaseChannelServiceImpl, error) {
+	channels, err := getReleaseChannelMatchers(logger, releaseChannelConfig.Channels)
+	if err != nil {
+		return nil, err
+	}
+	channelsByRepository := make(map[bean.Repository][]*releaseChannelMatcher)
+	for _, repository := range client.Repositories {
+		if repository.Channels == nil {
+			continue
+		}
+		repositoryChannels, err := getReleaseChannelMatchers(logger, repository.Channels)
+		if err != nil {
+			return nil, err
+		}
+		channelsByRepository[bean.Repository(repository.Alias)] = repositoryChannels
+	}
+	return &ReleaseChannelServiceImpl{
+		logger:               logger,
+		channels:             channels,
+		channelsByRepository: channelsByRepository,
+	}, nil
+}
+
+func getReleaseChannelMatchers(logger *zap.SugaredLogger, releaseChannels []*util.ReleaseChannel) ([]*releaseChannelMatcher, error) {
+	var channels []*releaseChannelMatcher
+	for _, channel := range releaseChannels {
+		if len(channel.Name) == 0 {
+			return nil, fmt.Errorf("release channel name is required")
+		}
+		matcher := &releaseChannelMatcher{
+			name:       channel.Name,
+			prerelease: channel.Prerelease,
+		}
+		if len(channel.TagPattern) > 0 {
+			tagPattern, err := regexp.Compile(channel.TagPattern)
+			if err != nil {
+				logger.Errorw("invalid tag pattern for release channel", "channel", channel.Name, "tagPattern", channel.TagPattern, "err", err)
+				return nil, err
+			}
+			matcher.tagPattern = tagPattern
+		}
+		if len(channel.VersionConstraint) > 0 {
+			versionConstraint, err := semver.NewConstraint(channel.VersionConstraint)
+			if err != nil {
+				logger.Errorw("invalid version constraint for release channel", "channel", channel.Name, "versionConstraint", channel.VersionConstraint, "err", err)
+				return nil, err
+			}
+			matcher.versionConstraint = versionConstraint
+		}
+		channels = append(channels, matcher)
+	}
+	return channels, nil
+}
+
+func (impl *ReleaseChannelServiceImpl) getChannels(repository bean.Repository) []*releaseChannelMatcher {
+	if channels, ok := impl.channelsByRepository[repository]; ok {
+		return channels
+	}
+	return impl.channels
+}
+
+func (impl *ReleaseChannelServiceImpl) GetChannelNames(repository bean.Repository) []string {
+	channels := impl.getChannels(repository)
+	names := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		names = append(names, channel.name)
+	}
+	return names
+}
+
+func (impl *ReleaseChannelServiceImpl) IsValidChannel(repository bean.Repository, name string) bool {
+	for _, channel := range impl.getChannels(repository) {
+		if channel.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (impl *ReleaseChannelServiceImpl) GetChannelsForRelease(repository bean.Repository, release *common.Release) []string {
+	channels := make([]string, 0)
+	for _, channel := range impl.getChannels(repository) {
+		if channel.matches(release) {
+			channels = append(channels, channel.name)
+		}
+	}
+	return channels
+}
+
+func (matcher *releaseChannelMatcher) matches(release *common.Release) bool {
+	if matcher.prerelease != nil && *matcher.prerelease != release.Prerelease {
+		return false
+	}
+	if matcher.tagPattern != nil && !matcher.tagPattern.MatchString(release.TagName) {
+		return false
+	}
+	if matcher.versionConstraint != nil {
+		version, err := semver.NewVersion(release.TagName)
+		if err != nil || !matcher.versionConstraint.Check(version) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"context"
+	"fmt"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"github.com/golang/groupcache/lru"
+	"github.com/google/go-github/github"
+	"go.uber.org/zap"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type ReleaseCompareService interface {
+	// CompareReleases returns the commits and merged pull requests from base tag to head tag
+	CompareReleases(repository bean.Repository, base string, head string) (*common.ReleaseComparison, error)
+}
+
+type ReleaseCompareServiceImpl struct {
+	logger               *zap.SugaredLogger
+	client               *util.GitHubClient
+	releaseCompareConfig *util.ReleaseCompareConfig
+	// comparisons keyed on repository and tag pair, tags are immutable so entries are only evicted for size
+	comparisonCache     *lru.Cache
+	comparisonCacheLock sync.Mutex
+}
+
+func NewReleaseCompareServiceImpl(logger *zap.SugaredLogger, client *util.GitHubClient,
+	releaseCompareConfig *util.ReleaseCompareConfig) *ReleaseCompareServiceImpl {
+	return &ReleaseCompareServiceImpl{
+		logger:               logger,
+		client:               client,
+		releaseCompareConfig: releaseCompareConfig,
+		comparisonCache:      lru.New(releaseCompareConfig.CacheSize),
+	}
+}
+
+// pullRequestReferenceRegex matches squash merge titles like "fix: x (#123)" and merge commits "Merge pull request #123 from"
+var pullRequestReferenceRegex = regexp.MustCompile(`\(#([0-9]+)\)\s*$|^Merge pull request #([0-9]+)`)
+
+func (impl *ReleaseCompareServiceImpl) CompareReleases(repository bean.Repository, base string, head string) (*common.ReleaseComparison, error) {
+	cacheKey := fmt.Sprintf("%s|%s|%s", repository, base, head)
+	impl.comparisonCacheLock.Lock()
+	cachedComparison, ok := impl.comparisonCache.Get(cacheKey)
+	impl.comparisonCacheLock.Unlock()
+	if ok {
+		return cachedComparison.(*common.ReleaseComparison), nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(impl.releaseCompareConfig.TimeoutInSecs)*time.Second)
+	defer cancel()
+	githubRepository := impl.client.GetRepository(repository.String())
+	githubComparison, _, err := githubRepository.Client.Repositories.CompareCommits(ctx, githubRepository.Owner, githubRepository.Name, base, head)
+	if err != nil {
+		impl.logger.Errorw("error in comparing releases on github", "repository", repository, "base", base, "head", head, "err", err)
+		return nil, err
+	}
+	comparison := &common.ReleaseComparison{
+		Base:         base,
+		Head:         head,
+		Status:       githubComparison.GetStatus(),
+		AheadBy:      githubComparison.GetAheadBy(),
+		BehindBy:     githubComparison.GetBehindBy(),
+		TotalCommits: githubComparison.GetTotalCommits(),
+		Truncated:    githubComparison.GetTotalCommits() > len(githubComparison.Commits),
+		CompareUrl:   githubComparison.GetHTMLURL(),
+		Commits:      make([]*common.ComparisonCommit, 0, len(githubComparison.Commits)),
+		PullRequests: make([]*common.ComparisonPullRequest, 0),
+	}
+	for _, githubCommit := range githubComparison.Commits {
+		commit := &common.ComparisonCommit{
+			Sha:          githubCommit.GetSHA(),
+			Summary:      strings.SplitN(githubCommit.GetCommit().GetMessage(), "\n", 2)[0],
+			AuthorName:   githubCommit.GetCommit().GetAuthor().GetName(),
+			AuthorLogin:  githubCommit.GetAuthor().GetLogin(),
+			Date:         githubCommit.GetCommit().GetAuthor().GetDate(),
+			Url:          githubCommit.GetHTMLURL(),
+			PullRequests: make([]int, 0),
+		}
+		comparison.Commits = append(comparison.Commits, commit)
+	}
+	pullRequests, complete := impl.getPullRequests(ctx, repository, comparison.Commits)
+	comparison.PullRequests = pullRequests
+	if complete {
+		// comparisons degraded by failed lookups are retried on the next request
+		impl.comparisonCacheLock.Lock()
+		impl.comparisonCache.Add(cacheKey, comparison)
+		impl.comparisonCacheLock.Unlock()
+	}
+	return comparison, nil
+}
+
+// getPullRequests looks up the pull requests of commits on github, up to the configured number of commits.
+// Pull requests of remaining commits and of failed lookups are read from the commit summary, the returned
+// flag is false when any lookup failed.
+func (impl *ReleaseCompareServiceImpl) getPullRequests(ctx context.Context, repository bean.Repository, commits []*common.ComparisonCommit) ([]*common.ComparisonPullRequest, bool) {
+	lookups := make([][]*github.PullRequest, len(commits))
+	lookupFailed := make([]bool, len(commits))
+	var failedLookups int32
+	concurrency := impl.releaseCompareConfig.PullRequestLookupConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	semaphore := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, commit := range commits {
+		if i >= impl.releaseCompareConfig.MaxPullRequestLookups {
+			lookupFailed[i] = true
+			continue
+		}
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, sha string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			pullRequests, err := impl.listPullRequestsWithCommit(ctx, repository, sha)
+			if err != nil {
+				impl.logger.Warnw("error in getting pull requests of commit, reading it from commit message", "repository", repository, "sha", sha, "err", err)
+				lookupFailed[i] = true
+				atomic.AddInt32(&failedLookups, 1)
+				return
+			}
+			lookups[i] = pullRequests
+		}(i, commit.Sha)
+	}
+	wg.Wait()
+
+	pullRequestsByNumber := make(map[int]*common.ComparisonPullRequest)
+	for i, commit := range commits {
+		if lookupFailed[i] {
+			number, title := getPullRequestFromCommitSummary(commit.Summary)
+			if number == 0 {
+				continue
+			}
+			commit.PullRequests = append(commit.PullRequests, number)
+			if _, ok := pullRequestsByNumber[number]; !ok {
+				pullRequestsByNumber[number] = &common.ComparisonPullRequest{Number: number, Title: title}
+			}
+			continue
+		}
+		for _, githubPullRequest := range lookups[i] {
+			// commits are also associated with open pull requests of other branches
+			if githubPullRequest.MergedAt == nil {
+				continue
+			}
+			number := githubPullRequest.GetNumber()
+			commit.PullRequests = append(commit.PullRequests, number)
+			pullRequestsByNumber[number] = &common.ComparisonPullRequest{
+				Number:      number,
+				Title:       githubPullRequest.GetTitle(),
+				Url:         githubPullRequest.GetHTMLURL(),
+				AuthorLogin: githubPullRequest.GetUser().GetLogin(),
+				MergedAt:    githubPullRequest.MergedAt,
+			}
+		}
+	}
+	pullRequests := make([]*common.ComparisonPullRequest, 0, len(pullRequestsByNumber))
+	for _, pullRequest := range pullRequestsByNumber {
+		pullRequests = append(pullRequests, pullRequest)
+	}
+	sort.Slice(pullRequests, func(i, j int) bool {
+		return pullRequests[i].Number < pullRequests[j].Number
+	})
+	return pullRequests, failedLookups == 0
+}
+
+// listPullRequestsWithCommit calls the commits api for pull requests associated with a commit, which is not
+// covered by the vendored github client
+func (impl *ReleaseCompareServiceImpl) listPullRequestsWithCommit(ctx context.Context, repository bean.Repository, sha string) ([]*github.PullRequest, error) {
+	githubRepository := impl.client.GetRepository(repository.String())
+	url := fmt.Sprintf("repos/%s/%s/commits/%s/pulls", githubRepository.Owner, githubRepository.Name, sha)
+	request, err := githubRepository.Client.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	// preview media type is still required by older github enterprise servers
+	request.Header.Set("Accept", "application/vnd.github.groot-preview+json")
+	var pullRequests []*github.PullRequest
+	_, err = githubRepository.Client.Do(ctx, request, &pullRequests)
+	if err != nil {
+		return nil, err
+	}
+	return pullRequests, nil
+}
+
+func getPullRequestFromCommitSummary(summary string) (int, string) {
+	match := pullRequestReferenceRegex.FindStringSubmatchIndex(summary)
+	if match == nil {
+		return 0, ""
+	}
+	if match[2] >= 0 {
+		number, _ := strconv.Atoi(summary[match[2]:match[3]])
+		return number, strings.TrimSpace(summary[:match[0]])
+	}
+	number, _ := strconv.Atoi(summary[match[4]:match[5]])
+	return number, summary
+}
@@ -0,0 +1,305 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"encoding/xml"
+	"fmt"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"go.uber.org/zap"
+	"net/url"
+	"sort"
+	"time"
+)
+
+type ReleaseFeedService interface {
+	// GetFeed returns the xml feed of published releases of a repository, limited to a channel when given.
+	// selfUrl is the absolute url the feed is served on, releases under rollout are listed only when the
+	// rollout includes the installation.
+	GetFeed(repository bean.Repository, channel string, format bean.ReleaseFeedFormat, selfUrl string, installationId string) ([]byte, error)
+}
+
+const releaseFeedEntryLimit = 50
+
+type ReleaseFeedServiceImpl struct {
+	logger              *zap.SugaredLogger
+	client              *util.GitHubClient
+	releaseNoteService  ReleaseNoteService
+	releaseNoteRenderer ReleaseNoteRenderer
+	rolloutService      RolloutService
+}
+
+func NewReleaseFeedServiceImpl(logger *zap.SugaredLogger, client *util.GitHubClient, releaseNoteService ReleaseNoteService,
+	releaseNoteRenderer ReleaseNoteRenderer, rolloutService RolloutService) *ReleaseFeedServiceImpl {
+	return &ReleaseFeedServiceImpl{
+		logger:              logger,
+		client:              client,
+		releaseNoteService:  releaseNoteService,
+		releaseNoteRenderer: releaseNoteRenderer,
+		rolloutService:      rolloutService,
+	}
+}
+
+func (impl *ReleaseFeedServiceImpl) GetFeed(repository bean.Repository, channel string, format bean.ReleaseFeedFormat, selfUrl string, installationId string) ([]byte, error) {
+	releases, err := impl.releaseNoteService.GetReleases(repository)
+	if err != nil {
+		impl.logger.Errorw("error in getting releases for feed", "repository", repository, "err", err)
+		return nil, err
+	}
+	releases = impl.rolloutService.FilterReleases(repository, releases, installationId)
+	// release bodies are rendered from the renderer cache, so feeds are cheap to build on every request
+	feedReleases := getFeedReleases(releases, channel)
+	var feed []byte
+	switch format {
+	case bean.ReleaseFeedFormatAtom:
+		feed, err = impl.buildAtomFeed(repository, channel, feedReleases, selfUrl)
+	case bean.ReleaseFeedFormatRss:
+		feed, err = impl.buildRssFeed(repository, channel, feedReleases, selfUrl)
+	default:
+		err = fmt.Errorf("unsupported feed format %s", format)
+	}
+	if err != nil {
+		impl.logger.Errorw("error in building release feed", "repository", repository, "channel", channel, "format", format, "err", err)
+		return nil, err
+	}
+	return feed, nil
+}
+
+// getFeedReleases returns the latest published releases, stable ones only unless a channel is given
+// in which case channel rules decide on prereleases
+func getFeedReleases(releases []*common.Release, channel string) []*common.Release {
+	var feedReleases []*common.Release
+	for _, release := range releases {
+		if release.Draft {
+			continue
+		}
+		if len(channel) == 0 && release.Prerelease {
+			continue
+		}
+		if len(channel) > 0 && !containsString(release.Channels, channel) {
+			continue
+		}
+		feedReleases = append(feedReleases, release)
+	}
+	sort.SliceStable(feedReleases, func(i, j int) bool {
+		return getReleaseUpdatedTime(feedReleases[i]).After(getReleaseUpdatedTime(feedReleases[j]))
+	})
+	if len(feedReleases) > releaseFeedEntryLimit {
+		feedReleases = feedReleases[:releaseFeedEntryLimit]
+	}
+	return feedReleases
+}
+
+func getReleaseUpdatedTime(release *common.Release) time.Time {
+	if release.PublishedAt.IsZero() {
+		return release.CreatedAt
+	}
+	return release.PublishedAt
+}
+
+func (impl *ReleaseFeedServiceImpl) getReleasesUrl(repository bean.Repository) string {
+	return fmt.Sprintf("%s/releases", impl.client.GetRepository(repository.String()).GetWebUrl())
+}
+
+func (impl *ReleaseFeedServiceImpl) getReleaseUrl(repository bean.Repository, release *common.Release) string {
+	if len(release.TagLink) > 0 {
+		return release.TagLink
+	}
+	return fmt.Sprintf("%s/tag/%s", impl.getReleasesUrl(repository), release.TagName)
+}
+
+func getFeedTitle(repository bean.Repository, channel string) string {
+	title := fmt.Sprintf("%s releases", repository)
+	if len(channel) > 0 {
+		title = fmt.Sprintf("%s (%s channel)", title, channel)
+	}
+	return title
+}
+
+func getReleaseEntryTitle(release *common.Release) string {
+	if len(release.ReleaseName) > 0 {
+		return release.ReleaseName
+	}
+	return release.TagName
+}
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"http://www.w3.org/2005/Atom feed"`
+	Id      string       `xml:"id"`
+	Title   string       `xml:"title"`
+	Updated string       `xml:"updated"`
+	Links   []*atomLink  `xml:"link"`
+	Author  *atomAuthor  `xml:"author"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	Id        string       `xml:"id"`
+	Title     string       `xml:"title"`
+	Updated   string       `xml:"updated"`
+	Published string       `xml:"published,omitempty"`
+	Links     []*atomLink  `xml:"link"`
+	Content   *atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func (impl *ReleaseFeedServiceImpl) buildAtomFeed(repository bean.Repository, channel string, releases []*common.Release, selfUrl string) ([]byte, error) {
+	releasesUrl := impl.getReleasesUrl(repository)
+	feed := &atomFeed{
+		Id:    getAtomFeedId(releasesUrl, channel),
+		Title: getFeedTitle(repository, channel),
+		Links: []*atomLink{
+			{Rel: "self", Type: "application/atom+xml", Href: selfUrl},
+			{Rel: "alternate", Type: "text/html", Href: releasesUrl},
+		},
+		Author: &atomAuthor{Name: impl.client.GetRepository(repository.String()).Owner},
+	}
+	// an empty feed is updated at epoch so that its ETag stays stable
+	updated := time.Unix(0, 0).UTC()
+	for _, release := range releases {
+		content, err := impl.releaseNoteRenderer.Render(release.Body, bean.ReleaseNoteFormatHtml, repository)
+		if err != nil {
+			return nil, err
+		}
+		releaseUrl := impl.getReleaseUrl(repository, release)
+		releaseUpdated := getReleaseUpdatedTime(release).UTC()
+		if releaseUpdated.After(updated) {
+			updated = releaseUpdated
+		}
+		entry := &atomEntry{
+			Id:      releaseUrl,
+			Title:   getReleaseEntryTitle(release),
+			Updated: releaseUpdated.Format(time.RFC3339),
+			Links:   []*atomLink{{Rel: "alternate", Type: "text/html", Href: releaseUrl}},
+			Content: &atomContent{Type: "html", Body: content},
+		}
+		if !release.PublishedAt.IsZero() {
+			entry.Published = release.PublishedAt.UTC().Format(time.RFC3339)
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	feed.Updated = updated.Format(time.RFC3339)
+	return marshalFeed(feed)
+}
+
+// getAtomFeedId identifies the feed by the releases url of the repository and the channel, the self url depends
+// on the host and scheme the feed is requested on
+func getAtomFeedId(releasesUrl string, channel string) string {
+	if len(channel) == 0 {
+		return releasesUrl
+	}
+	return fmt.Sprintf("%s?channel=%s", releasesUrl, url.QueryEscape(channel))
+}
+
+type rssFeed struct {
+	XMLName   xml.Name    `xml:"rss"`
+	Version   string      `xml:"version,attr"`
+	AtomSpace string      `xml:"xmlns:atom,attr"`
+	Channel   *rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	LastBuildDate string     `xml:"lastBuildDate,omitempty"`
+	AtomLink      *rssLink   `xml:"atom:link"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+	Href string `xml:"href,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Guid        *rssGuid `xml:"guid"`
+	PubDate     string   `xml:"pubDate,omitempty"`
+	Description string   `xml:"description"`
+}
+
+type rssGuid struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func (impl *ReleaseFeedServiceImpl) buildRssFeed(repository bean.Repository, channel string, releases []*common.Release, selfUrl string) ([]byte, error) {
+	title := getFeedTitle(repository, channel)
+	feed := &rssFeed{
+		Version:   "2.0",
+		AtomSpace: "http://www.w3.org/2005/Atom",
+		Channel: &rssChannel{
+			Title:       title,
+			Link:        impl.getReleasesUrl(repository),
+			Description: title,
+			AtomLink:    &rssLink{Rel: "self", Type: "application/rss+xml", Href: selfUrl},
+		},
+	}
+	var lastBuild time.Time
+	for _, release := range releases {
+		content, err := impl.releaseNoteRenderer.Render(release.Body, bean.ReleaseNoteFormatHtml, repository)
+		if err != nil {
+			return nil, err
+		}
+		releaseUrl := impl.getReleaseUrl(repository, release)
+		releaseUpdated := getReleaseUpdatedTime(release).UTC()
+		if releaseUpdated.After(lastBuild) {
+			lastBuild = releaseUpdated
+		}
+		item := &rssItem{
+			Title:       getReleaseEntryTitle(release),
+			Link:        releaseUrl,
+			Guid:        &rssGuid{IsPermaLink: true, Value: releaseUrl},
+			Description: content,
+		}
+		if !releaseUpdated.IsZero() {
+			item.PubDate = releaseUpdated.Format(time.RFC1123Z)
+		}
+		feed.Channel.Items = append(feed.Channel.Items, item)
+	}
+	if !lastBuild.IsZero() {
+		feed.Channel.LastBuildDate = lastBuild.Format(time.RFC1123Z)
+	}
+	return marshalFeed(feed)
+}
+
+func marshalFeed(feed interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/devtron-labs/central-api/common"
+)
+
+// ReleaseFilter selects releases by version, channel and prerelease flag, the zero value keeps all
+// published non pre-releases
+type ReleaseFilter struct {
+	// ServerVersion keeps the release of that version and newer ones
+	ServerVersion     *semver.Version
+	VersionConstraint *semver.Constraints
+	IncludePrerelease bool
+	// Channel decides on prerelease membership when set, IncludePrerelease is ignored then
+	Channel string
+}
+
+func (filter *ReleaseFilter) HasVersionFilter() bool {
+	return filter.ServerVersion != nil || filter.VersionConstraint != nil
+}
+
+// FilterReleases returns the matching releases sorted newest first by semver, along with the tags which are not semver
+func FilterReleases(releases []*common.Release, filter *ReleaseFilter) ([]*common.Release, []string) {
+	if len(filter.Channel) > 0 {
+		// channel rules decide on prerelease membership
+		releases = FilterReleasesByChannel(FilterPublishedReleases(releases, true), filter.Channel)
+	} else {
+		releases = FilterPublishedReleases(releases, filter.IncludePrerelease)
+	}
+	releases, nonSemverTags := common.SortReleasesBySemver(releases)
+	if !filter.HasVersionFilter() {
+		return releases, nonSemverTags
+	}
+	// get all releases matching the version filters, tags which are not semver never match
+	var filteredReleases []*common.Release
+	for _, release := range releases {
+		version, err := semver.NewVersion(release.TagName)
+		if err != nil {
+			continue
+		}
+		// include matching version and newer versions
+		if filter.ServerVersion != nil && !version.Equal(filter.ServerVersion) && !version.GreaterThan(filter.ServerVersion) {
+			continue
+		}
+		if filter.VersionConstraint != nil && !filter.VersionConstraint.Check(version) {
+			continue
+		}
+		filteredReleases = append(filteredReleases, release)
+	}
+	return filteredReleases, nonSemverTags
+}
+
+// FilterPublishedReleases drops drafts always and prereleases unless includePrerelease is set
+func FilterPublishedReleases(releases []*common.Release, includePrerelease bool) []*common.Release {
+	var filteredReleases []*common.Release
+	for _, release := range releases {
+		if release.Draft || (release.Prerelease && !includePrerelease) {
+			continue
+		}
+		filteredReleases = append(filteredReleases, release)
+	}
+	return filteredReleases
+}
+
+func FilterReleasesByChannel(releases []*common.Release, channel string) []*common.Release {
+	var filteredReleases []*common.Release
+	for _, release := range releases {
+		for _, releaseChannel := range release.Channels {
+			if releaseChannel == channel {
+				filteredReleases = append(filteredReleases, release)
+				break
+			}
+		}
+	}
+	return filteredReleases
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"github.com/golang/groupcache/lru"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+type ReleaseManifestService interface {
+	// GetComponentManifest returns the images listed in the component manifest asset of a release,
+	// nil when the release has no such asset
+	GetComponentManifest(repository bean.Repository, release *common.Release) (*common.ReleaseComponentManifest, error)
+}
+
+type ReleaseManifestServiceImpl struct {
+	logger                *zap.SugaredLogger
+	client                *util.GitHubClient
+	releaseManifestConfig *util.ReleaseManifestConfig
+	httpClient            *http.Client
+	// parsed manifests keyed on repository, tag and asset, an asset replaced on release edit gets a new id and
+	// its old entry is evicted for size
+	manifestCache     *lru.Cache
+	manifestCacheLock sync.Mutex
+}
+
+func NewReleaseManifestServiceImpl(logger *zap.SugaredLogger, client *util.GitHubClient,
+	releaseManifestConfig *util.ReleaseManifestConfig) *ReleaseManifestServiceImpl {
+	return &ReleaseManifestServiceImpl{
+		logger:                logger,
+		client:                client,
+		releaseManifestConfig: releaseManifestConfig,
+		httpClient:            &http.Client{Timeout: time.Duration(releaseManifestConfig.ManifestTimeoutInSecs) * time.Second},
+		manifestCache:         lru.New(releaseManifestConfig.ManifestCacheSize),
+	}
+}
+
+func (impl *ReleaseManifestServiceImpl) GetComponentManifest(repository bean.Repository, release *common.Release) (*common.ReleaseComponentManifest, error) {
+	asset := impl.getManifestAsset(release)
+	if asset == nil {
+		return nil, nil
+	}
+	cacheKey := fmt.Sprintf("%s|%s|%d|%d", repository, release.TagName, asset.Id, asset.Size)
+	impl.manifestCacheLock.Lock()
+	cachedManifest, ok := impl.manifestCache.Get(cacheKey)
+	impl.manifestCacheLock.Unlock()
+	if ok {
+		return cachedManifest.(*common.ReleaseComponentManifest), nil
+	}
+	content, err := impl.downloadAsset(repository, asset)
+	if err != nil {
+		impl.logger.Errorw("error in downloading component manifest asset", "repository", repository, "tagName", release.TagName, "asset", asset.Name, "err", err)
+		return nil, err
+	}
+	images, err := parseComponentManifest(asset.Name, content)
+	if err != nil {
+		impl.logger.Errorw("error in parsing component manifest asset", "repository", repository, "tagName", release.TagName, "asset", asset.Name, "err", err)
+		return nil, err
+	}
+	manifest := &common.ReleaseComponentManifest{
+		TagName: release.TagName,
+		Source:  asset.Name,
+		Images:  images,
+	}
+	impl.manifestCacheLock.Lock()
+	impl.manifestCache.Add(cacheKey, manifest)
+	impl.manifestCacheLock.Unlock()
+	return manifest, nil
+}
+
+func (impl *ReleaseManifestServiceImpl) getManifestAsset(release *common.Release) *common.ReleaseAsset {
+	for _, assetName := range impl.releaseManifestConfig.ManifestAssetNames {
+		for _, asset := range release.Assets {
+			if strings.EqualFold(asset.Name, strings.TrimSpace(assetName)) {
+				return asset
+			}
+		}
+	}
+	return nil
+}
+
+func (impl *ReleaseManifestServiceImpl) downloadAsset(repository bean.Repository, asset *common.ReleaseAsset) ([]byte, error) {
+	maxSize := impl.releaseManifestConfig.ManifestMaxSizeBytes
+	if int64(asset.Size) > maxSize {
+		return nil, fmt.Errorf("asset %s of %d bytes exceeds the limit of %d bytes", asset.Name, asset.Size, maxSize)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), impl.httpClient.Timeout)
+	defer cancel()
+	githubRepository := impl.client.GetRepository(repository.String())
+	reader, redirectUrl, err := githubRepository.Client.Repositories.DownloadReleaseAsset(ctx, githubRepository.Owner, githubRepository.Name, asset.Id)
+	if err != nil {
+		return nil, err
+	}
+	if reader == nil {
+		// assets are served from a storage url github redirects to, which does not need the github token
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, redirectUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+		response, err := impl.httpClient.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			return nil, fmt.Errorf("unexpected status %d on downloading asset %s", response.StatusCode, asset.Name)
+		}
+		reader = response.Body
+	}
+	defer reader.Close()
+	content, err := ioutil.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(content)) > maxSize {
+		return nil, fmt.Errorf("asset %s exceeds the limit of %d bytes", asset.Name, maxSize)
+	}
+	return content, nil
+}
+
+// componentManifestEntry is an image of a yaml manifest, either a plain image reference or a name and image pair
+type componentManifestEntry struct {
+	Name  string `yaml:"name"`
+	Image string `yaml:"image"`
+}
+
+func (entry *componentManifestEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var image string
+	if err := unmarshal(&image); err == nil {
+		entry.Image = image
+		return nil
+	}
+	type plainEntry componentManifestEntry
+	return unmarshal((*plainEntry)(entry))
+}
+
+type componentManifestFile struct {
+	Images     []*componentManifestEntry `yaml:"images"`
+	Components []*componentManifestEntry `yaml:"components"`
+}
+
+// parseComponentManifest reads a manifest asset, text assets hold an image reference per line and '#' comments,
+// yaml (and json) assets hold a list of entries either at top level or under images or components
+func parseComponentManifest(assetName string, content []byte) ([]*common.ComponentImage, error) {
+	var entries []*componentManifestEntry
+	switch strings.ToLower(path.Ext(assetName)) {
+	case ".yaml", ".yml", ".json":
+		manifestFile := &componentManifestFile{}
+		if err := yaml.Unmarshal(content, manifestFile); err == nil {
+			entries = append(manifestFile.Images, manifestFile.Components...)
+		} else if err := yaml.Unmarshal(content, &entries); err != nil {
+			return nil, fmt.Errorf("invalid manifest %s: %s", assetName, err.Error())
+		}
+	default:
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if len(line) == 0 || strings.HasPrefix(line, "#") {
+				continue
+			}
+			entries = append(entries, &componentManifestEntry{Image: line})
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	}
+	images := make([]*common.ComponentImage, 0, len(entries))
+	for _, entry := range entries {
+		if entry == nil || len(strings.TrimSpace(entry.Image)) == 0 {
+			continue
+		}
+		image := parseImageReference(strings.TrimSpace(entry.Image))
+		if len(entry.Name) > 0 {
+			image.Name = entry.Name
+		}
+		images = append(images, image)
+	}
+	return images, nil
+}
+
+// parseImageReference splits an image reference like quay.io/devtron/dashboard:v1@sha256:... into its parts,
+// the component name defaults to the last path element of the repository
+func parseImageReference(reference string) *common.ComponentImage {
+	image := &common.ComponentImage{Image: reference}
+	repository := reference
+	if index := strings.Index(repository, "@"); index >= 0 {
+		image.Digest = repository[index+1:]
+		repository = repository[:index]
+	}
+	// a colon after the last slash separates the tag, before it belongs to a registry port
+	if index := strings.LastIndex(repository, ":"); index > strings.LastIndex(repository, "/") {
+		image.Tag = repository[index+1:]
+		repository = repository[:index]
+	}
+	image.Repository = repository
+	image.Name = path.Base(repository)
+	return image
+}
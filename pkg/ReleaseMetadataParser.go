@@ -0,0 +1,104 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/devtron-labs/central-api/common"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"gopkg.in/yaml.v2"
+	"sort"
+	"strings"
+	"time"
+)
+
+// parseReleaseMetadata reads the release metadata html comment of a release body, see bean.ReleaseMetadataMatcher.
+// Nil is returned when the body has no metadata, invalid metadata is returned along with the validation errors.
+// Warnings do not invalidate the metadata.
+func parseReleaseMetadata(body string) (*common.ReleaseCompatibility, []string, []string) {
+	start := strings.Index(body, bean.ReleaseMetadataMatcher)
+	if start < 0 {
+		return nil, nil, nil
+	}
+	content := body[start+len(bean.ReleaseMetadataMatcher):]
+	end := strings.Index(content, bean.ReleaseMetadataEndMatcher)
+	if end < 0 {
+		return nil, []string{"release metadata comment is not closed"}, nil
+	}
+	var warnings []string
+	if strings.Contains(content[end:], bean.ReleaseMetadataMatcher) {
+		warnings = append(warnings, "release metadata is declared more than once, only the first block is read")
+	}
+	compatibility := &common.ReleaseCompatibility{}
+	err := yaml.UnmarshalStrict([]byte(content[:end]), compatibility)
+	if err != nil {
+		return nil, []string{fmt.Sprintf("release metadata is invalid yaml: %s", err.Error())}, warnings
+	}
+	return compatibility, validateReleaseCompatibility(compatibility), warnings
+}
+
+func validateReleaseCompatibility(compatibility *common.ReleaseCompatibility) []string {
+	var validationErrors []string
+	if len(compatibility.KubernetesVersions) > 0 {
+		if _, err := semver.NewConstraint(compatibility.KubernetesVersions); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("kubernetesVersions %q is not a version constraint", compatibility.KubernetesVersions))
+		}
+	}
+	if len(compatibility.HelmChartVersion) > 0 {
+		if _, err := semver.NewVersion(compatibility.HelmChartVersion); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("helmChartVersion %q is not a semver version", compatibility.HelmChartVersion))
+		}
+	}
+	modules := make([]string, 0, len(compatibility.MinModuleVersions))
+	for module := range compatibility.MinModuleVersions {
+		modules = append(modules, module)
+	}
+	// sorted for stable error messages
+	sort.Strings(modules)
+	for _, module := range modules {
+		if _, err := semver.NewVersion(compatibility.MinModuleVersions[module]); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("minModuleVersions of %s %q is not a semver version", module, compatibility.MinModuleVersions[module]))
+		}
+	}
+	if len(compatibility.EndOfLife) > 0 {
+		if _, err := time.Parse(bean.ReleaseMetadataDateLayout, compatibility.EndOfLife); err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("endOfLife %q is not a date of format %s", compatibility.EndOfLife, bean.ReleaseMetadataDateLayout))
+		}
+	}
+	return validationErrors
+}
+
+// IsKubernetesVersionSupported checks a kubernetes version against the constraint of a release, releases without
+// a constraint support every version. Pre-release and build suffixes of the version like -eks-1 are ignored.
+func IsKubernetesVersionSupported(compatibility *common.ReleaseCompatibility, kubernetesVersion *semver.Version) bool {
+	if len(compatibility.KubernetesVersions) == 0 {
+		return true
+	}
+	constraint, err := semver.NewConstraint(compatibility.KubernetesVersions)
+	if err != nil {
+		return false
+	}
+	version := semver.New(kubernetesVersion.Major(), kubernetesVersion.Minor(), kubernetesVersion.Patch(), "", "")
+	return constraint.Check(version)
+}
+
+// IsEndOfLifeReached tells if support of the release ended by the given time
+func IsEndOfLifeReached(compatibility *common.ReleaseCompatibility, now time.Time) bool {
+	endOfLife, err := time.Parse(bean.ReleaseMetadataDateLayout, compatibility.EndOfLife)
+	return err == nil && !now.Before(endOfLife)
+}
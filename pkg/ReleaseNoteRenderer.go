@@ -0,0 +1,193 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"github.com/golang/groupcache/lru"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/renderer/html"
+	"github.com/yuin/goldmark/text"
+	goldmarkUtil "github.com/yuin/goldmark/util"
+	"go.uber.org/zap"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type ReleaseNoteRenderer interface {
+	// Render converts a github markdown release body into the requested format, see bean.ReleaseNoteFormat
+	Render(body string, format bean.ReleaseNoteFormat, repository bean.Repository) (string, error)
+}
+
+// releaseNoteRenderCacheSize bounds the rendered bodies kept in memory, edited releases leave their previous
+// bodies behind which are evicted as least recently used
+const releaseNoteRenderCacheSize = 2000
+
+type ReleaseNoteRendererImpl struct {
+	logger   *zap.SugaredLogger
+	client   *util.GitHubClient
+	markdown goldmark.Markdown
+	// rendered output keyed on format, repository and body hash, bodies only change on release edits
+	renderCache     *lru.Cache
+	renderCacheLock sync.Mutex
+}
+
+func NewReleaseNoteRendererImpl(logger *zap.SugaredLogger, client *util.GitHubClient) *ReleaseNoteRendererImpl {
+	markdown := goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+		goldmark.WithParserOptions(
+			parser.WithAutoHeadingID(),
+			parser.WithASTTransformers(goldmarkUtil.Prioritized(&referenceLinkTransformer{}, 999)),
+		),
+		// raw html is let through here and cleaned up by sanitizeHtml afterwards
+		goldmark.WithRendererOptions(html.WithUnsafe()),
+	)
+	return &ReleaseNoteRendererImpl{
+		logger:      logger,
+		client:      client,
+		markdown:    markdown,
+		renderCache: lru.New(releaseNoteRenderCacheSize),
+	}
+}
+
+var referenceBaseUrlContextKey = parser.NewContextKey()
+
+func (impl *ReleaseNoteRendererImpl) Render(body string, format bean.ReleaseNoteFormat, repository bean.Repository) (string, error) {
+	switch format {
+	case "", bean.ReleaseNoteFormatMarkdown:
+		return body, nil
+	case bean.ReleaseNoteFormatHtml, bean.ReleaseNoteFormatText:
+	default:
+		return "", fmt.Errorf("unsupported release note format %s", format)
+	}
+	cacheKey := fmt.Sprintf("%s|%s|%x", format, repository, sha256.Sum256([]byte(body)))
+	impl.renderCacheLock.Lock()
+	cachedRendered, ok := impl.renderCache.Get(cacheKey)
+	impl.renderCacheLock.Unlock()
+	if ok {
+		return cachedRendered.(string), nil
+	}
+	var buf bytes.Buffer
+	ctx := parser.NewContext()
+	ctx.Set(referenceBaseUrlContextKey, impl.getReferenceBaseUrl(repository))
+	err := impl.markdown.Convert([]byte(body), &buf, parser.WithContext(ctx))
+	if err != nil {
+		impl.logger.Errorw("error in rendering release note markdown", "repository", repository, "err", err)
+		return "", err
+	}
+	rendered := sanitizeHtml(buf.String())
+	if format == bean.ReleaseNoteFormatText {
+		rendered = htmlToText(rendered)
+	}
+	impl.renderCacheLock.Lock()
+	impl.renderCache.Add(cacheKey, rendered)
+	impl.renderCacheLock.Unlock()
+	return rendered, nil
+}
+
+func (impl *ReleaseNoteRendererImpl) getReferenceBaseUrl(repository bean.Repository) *referenceBaseUrl {
+	githubRepository := impl.client.GetRepository(repository.String())
+	return &referenceBaseUrl{
+		userUrl:  strings.TrimSuffix(githubRepository.Host, "/"),
+		issueUrl: fmt.Sprintf("%s/issues", githubRepository.GetWebUrl()),
+	}
+}
+
+type referenceBaseUrl struct {
+	userUrl  string
+	issueUrl string
+}
+
+// referenceRegex matches github issue/pull request references like #123 and user mentions like @user
+var referenceRegex = regexp.MustCompile(`#([0-9]+)|@([A-Za-z0-9](?:[A-Za-z0-9]|-[A-Za-z0-9]){0,38})`)
+
+// referenceLinkTransformer turns #123 and @user references in plain text into links to the configured github host
+type referenceLinkTransformer struct{}
+
+func (t *referenceLinkTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	baseUrl, ok := pc.Get(referenceBaseUrlContextKey).(*referenceBaseUrl)
+	if !ok {
+		return
+	}
+	source := reader.Source()
+	var textNodes []*ast.Text
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch node.Kind() {
+		case ast.KindLink, ast.KindAutoLink, ast.KindImage, ast.KindCodeSpan, ast.KindCodeBlock,
+			ast.KindFencedCodeBlock, ast.KindHTMLBlock, ast.KindRawHTML:
+			return ast.WalkSkipChildren, nil
+		case ast.KindText:
+			textNodes = append(textNodes, node.(*ast.Text))
+		}
+		return ast.WalkContinue, nil
+	})
+	for _, textNode := range textNodes {
+		t.linkReferences(textNode, source, baseUrl)
+	}
+}
+
+func (t *referenceLinkTransformer) linkReferences(textNode *ast.Text, source []byte, baseUrl *referenceBaseUrl) {
+	segment := textNode.Segment
+	value := segment.Value(source)
+	matches := referenceRegex.FindAllSubmatchIndex(value, -1)
+	if len(matches) == 0 {
+		return
+	}
+	parent := textNode.Parent()
+	position := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if start > 0 && isReferenceWordCharacter(value[start-1]) || end < len(value) && isReferenceWordCharacter(value[end]) {
+			// part of a word, an email address or a path
+			continue
+		}
+		var destination string
+		if match[2] >= 0 {
+			destination = fmt.Sprintf("%s/%s", baseUrl.issueUrl, value[match[2]:match[3]])
+		} else {
+			destination = fmt.Sprintf("%s/%s", baseUrl.userUrl, value[match[4]:match[5]])
+		}
+		if start > position {
+			parent.InsertBefore(parent, textNode, ast.NewTextSegment(text.NewSegment(segment.Start+position, segment.Start+start)))
+		}
+		link := ast.NewLink()
+		link.Destination = []byte(destination)
+		link.AppendChild(link, ast.NewTextSegment(text.NewSegment(segment.Start+start, segment.Start+end)))
+		parent.InsertBefore(parent, textNode, link)
+		position = end
+	}
+	if position == 0 {
+		return
+	}
+	// the original node keeps the remainder so that line breaks are preserved
+	textNode.Segment = text.NewSegment(segment.Start+position, segment.Stop)
+}
+
+func isReferenceWordCharacter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '/' || c == '@' || c == '#' || c == '&'
+}
@@ -25,7 +25,9 @@ import (
 	"github.com/devtron-labs/central-api/pkg/bean"
 	blob_storage "github.com/devtron-labs/common-lib/blob-storage"
 	"github.com/google/go-github/github"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"net/url"
 	"os"
 	"strings"
 	"sync"
@@ -39,36 +41,70 @@ type ReleaseNoteService interface {
 	GetModulesV2() ([]*common.Module, error)
 	GetModuleByName(name string) (*common.Module, error)
 	GetReleasesOnInitialisation(repository bean.Repository) error
+	ValidatePrerequisites(body string) *common.PrerequisiteValidationResult
+	ValidateReleaseMetadata(body string) *common.ReleaseMetadataValidationResult
+	MergeReleaseSections(releases []*common.Release) []*common.ReleaseSection
+	// MergeReleaseNotices collects the breaking changes and deprecations of the releases, see bean.BreakingChangesMatcher
+	MergeReleaseNotices(releases []*common.Release) *common.BreakingChanges
+	GetReleaseByTag(repository bean.Repository, tagName string) (*common.Release, error)
+	GetLatestRelease(repository bean.Repository, channel string) (*common.Release, error)
+	AddReleaseCacheListener(listener ReleaseCacheListener)
+	AddReleaseEventListener(listener ReleaseEventListener)
 }
 
 type ReleaseNoteServiceImpl struct {
-	logger             *zap.SugaredLogger
-	client             *util.GitHubClient
-	mutex              sync.Mutex
-	moduleConfig       *util.ModuleConfig
-	blobConfig         *util.BlobConfigVariables
-	blobStorageService *blob_storage.BlobStorageServiceImpl
-	repoCacheMap       map[string]bool
+	logger                *zap.SugaredLogger
+	client                *util.GitHubClient
+	mutex                 sync.Mutex
+	moduleConfig          *util.ModuleConfig
+	blobConfig            *util.BlobConfigVariables
+	blobStorageService    *blob_storage.BlobStorageServiceImpl
+	repoCacheMap          map[string]bool
+	releaseChannelService ReleaseChannelService
+	indexMutex            sync.RWMutex
+	releaseIndexMap       map[string]*releaseIndex
+	releaseCacheListeners []ReleaseCacheListener
+	releaseEventListeners []ReleaseEventListener
+}
+
+// ReleaseCacheListener is notified with all the releases of a repository whenever they are updated in cache
+type ReleaseCacheListener interface {
+	OnReleaseCacheUpdated(repository bean.Repository, releases []*common.Release)
+}
+
+// ReleaseEventListener is notified of releases published or edited on github, drafts are not notified
+type ReleaseEventListener interface {
+	OnReleaseEvent(event *common.ReleaseEvent)
+}
+
+// releaseIndex is rebuilt from releaseCache of a repository whenever it changes
+type releaseIndex struct {
+	releaseByTag map[string]*common.Release
+	// latest published release by semver keyed on channel, bean.DefaultReleaseChannel holds the latest stable release
+	latestReleaseByChannel map[string]*common.Release
 }
 
 func NewReleaseNoteServiceImpl(logger *zap.SugaredLogger, client *util.GitHubClient,
-	moduleConfig *util.ModuleConfig, blobConfig *util.BlobConfigVariables, blobStorageService *blob_storage.BlobStorageServiceImpl) (*ReleaseNoteServiceImpl, error) {
+	moduleConfig *util.ModuleConfig, blobConfig *util.BlobConfigVariables, blobStorageService *blob_storage.BlobStorageServiceImpl,
+	releaseChannelService ReleaseChannelService) (*ReleaseNoteServiceImpl, error) {
 	repoCacheMap := make(map[string]bool)
-	for _, repo := range client.GitHubConfig.GitHubRepo {
-		repoCacheMap[repo] = true
+	for _, repository := range client.Repositories {
+		repoCacheMap[repository.Alias] = true
 	}
 	serviceImpl := &ReleaseNoteServiceImpl{
-		logger:             logger,
-		client:             client,
-		moduleConfig:       moduleConfig,
-		blobConfig:         blobConfig,
-		blobStorageService: blobStorageService,
-		repoCacheMap:       repoCacheMap,
+		logger:                logger,
+		client:                client,
+		moduleConfig:          moduleConfig,
+		blobConfig:            blobConfig,
+		blobStorageService:    blobStorageService,
+		repoCacheMap:          repoCacheMap,
+		releaseChannelService: releaseChannelService,
+		releaseIndexMap:       make(map[string]*releaseIndex),
 	}
 	// Async Call for getting releases from Github
 	serviceImpl.logger.Infow("getting release from github")
-	for _, repo := range client.GitHubConfig.GitHubRepo {
-		err := serviceImpl.GetReleasesOnInitialisation(bean.Repository(repo))
+	for _, repository := range client.Repositories {
+		err := serviceImpl.GetReleasesOnInitialisation(bean.Repository(repository.Alias))
 		if err != nil {
 			logger.Errorw("error in getting releases from github", "err", err)
 			return nil, err
@@ -108,6 +144,8 @@ func (impl *ReleaseNoteServiceImpl) UpdateReleases(requestBodyBytes []byte) (boo
 	}
 	body := releaseData["body"].(string)
 	tagLink := releaseData["html_url"].(string)
+	prerelease, _ := releaseData["prerelease"].(bool)
+	draft, _ := releaseData["draft"].(bool)
 	releaseInfo := &common.Release{
 		TagName:     tagName,
 		ReleaseName: releaseName,
@@ -115,37 +153,210 @@ func (impl *ReleaseNoteServiceImpl) UpdateReleases(requestBodyBytes []byte) (boo
 		CreatedAt:   createdAt,
 		PublishedAt: publishedAt,
 		TagLink:     tagLink,
+		Prerelease:  prerelease,
+		Draft:       draft,
+		Assets:      getReleaseAssetsFromWebhook(releaseData),
 	}
-	impl.getPrerequisiteContent(releaseInfo)
+	repo := impl.getRepositoryFromWebhook(data["repository"].(map[string]interface{}))
+	impl.enrichRelease(repo, releaseInfo)
 
 	//updating cache, fetch existing object and append new item
 	var releaseList []*common.Release
 	var releaseNotes []*common.Release
-	repo := bean.Repository(data["repository"].(map[string]interface{})["name"].(string))
 	cacheKey := bean.GetCacheKeyBasedOnRepo(bean.Repository(repo))
 
-	releaseNotes = releaseCache[cacheKey]
+	releaseNotes = impl.getCachedReleases(cacheKey)
 
 	if len(releaseNotes) > 0 {
 		releaseList = append(releaseList, releaseNotes...)
 	}
 
 	isNew := true
-	for _, release := range releaseList {
+	for i, release := range releaseList {
 		// tag is mandatory while drafting a new release
 		if release.TagName == releaseInfo.TagName {
-			release.ReleaseName = releaseInfo.ReleaseName
-			release.Body = releaseInfo.Body
+			// edited release replaces the cached one, fields derived from body are already recomputed
+			releaseList[i] = releaseInfo
 			isNew = false
 		}
 	}
 	if isNew {
 		releaseList = append([]*common.Release{releaseInfo}, releaseList...)
 	}
-	releaseCache[cacheKey] = releaseList
+	impl.setReleaseCache(repo, releaseList)
+	if !releaseInfo.Draft {
+		eventType := bean.ReleaseEventPublished
+		if action == bean.ActionEdited {
+			eventType = bean.ReleaseEventEdited
+		}
+		impl.notifyReleaseEvent(&common.ReleaseEvent{
+			Id:         uuid.New().String(),
+			Type:       eventType,
+			Repository: repo.String(),
+			Release:    releaseInfo,
+			CreatedOn:  time.Now(),
+		})
+	}
 	return impl.updateTagToBlobStorage(releaseInfo, repo)
 }
 
+// getRepositoryFromWebhook resolves the alias of the repository a webhook is received from, repositories
+// which are not configured keep their name
+func (impl *ReleaseNoteServiceImpl) getRepositoryFromWebhook(repositoryData map[string]interface{}) bean.Repository {
+	name, _ := repositoryData["name"].(string)
+	var owner, host string
+	if ownerData, ok := repositoryData["owner"].(map[string]interface{}); ok {
+		owner, _ = ownerData["login"].(string)
+	}
+	if htmlUrl, ok := repositoryData["html_url"].(string); ok {
+		if parsedUrl, err := url.Parse(htmlUrl); err == nil {
+			host = parsedUrl.Host
+		}
+	}
+	if repository := impl.client.GetRepositoryByFullName(host, owner, name); repository != nil {
+		return bean.Repository(repository.Alias)
+	}
+	impl.logger.Warnw("release webhook received from repository which is not configured", "owner", owner, "repo", name)
+	return bean.Repository(name)
+}
+
+func (impl *ReleaseNoteServiceImpl) AddReleaseEventListener(listener ReleaseEventListener) {
+	impl.indexMutex.Lock()
+	defer impl.indexMutex.Unlock()
+	impl.releaseEventListeners = append(impl.releaseEventListeners, listener)
+}
+
+func (impl *ReleaseNoteServiceImpl) notifyReleaseEvent(event *common.ReleaseEvent) {
+	impl.indexMutex.RLock()
+	listeners := impl.releaseEventListeners
+	impl.indexMutex.RUnlock()
+	for _, listener := range listeners {
+		listener.OnReleaseEvent(event)
+	}
+}
+
+// getReleaseAssetsFromWebhook reads the assets of a release webhook payload, numbers are decoded as float64
+func getReleaseAssetsFromWebhook(releaseData map[string]interface{}) []*common.ReleaseAsset {
+	assets := make([]*common.ReleaseAsset, 0)
+	assetsData, _ := releaseData["assets"].([]interface{})
+	for _, item := range assetsData {
+		assetData, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, _ := assetData["id"].(float64)
+		name, _ := assetData["name"].(string)
+		size, _ := assetData["size"].(float64)
+		downloadUrl, _ := assetData["browser_download_url"].(string)
+		contentType, _ := assetData["content_type"].(string)
+		downloadCount, _ := assetData["download_count"].(float64)
+		assets = append(assets, &common.ReleaseAsset{
+			Id:            int64(id),
+			Name:          name,
+			Size:          int(size),
+			DownloadUrl:   downloadUrl,
+			ContentType:   contentType,
+			DownloadCount: int(downloadCount),
+		})
+	}
+	return assets
+}
+
+// setReleaseCache updates releaseCache of a repository, rebuilds its index and notifies the cache listeners
+func (impl *ReleaseNoteServiceImpl) setReleaseCache(repository bean.Repository, releases []*common.Release) {
+	cacheKey := bean.GetCacheKeyBasedOnRepo(repository)
+	index := &releaseIndex{
+		releaseByTag:           make(map[string]*common.Release, len(releases)),
+		latestReleaseByChannel: make(map[string]*common.Release),
+	}
+	sortedReleases, _ := common.SortReleasesBySemver(releases)
+	// sorted newest first, so the first release seen for a channel is its latest
+	for _, release := range sortedReleases {
+		if release.Draft {
+			continue
+		}
+		index.releaseByTag[release.TagName] = release
+		if _, ok := index.latestReleaseByChannel[bean.DefaultReleaseChannel]; !ok && !release.Prerelease {
+			index.latestReleaseByChannel[bean.DefaultReleaseChannel] = release
+		}
+		for _, channel := range release.Channels {
+			if _, ok := index.latestReleaseByChannel[channel]; !ok {
+				index.latestReleaseByChannel[channel] = release
+			}
+		}
+	}
+	impl.indexMutex.Lock()
+	releaseCache[cacheKey] = releases
+	impl.releaseIndexMap[cacheKey] = index
+	listeners := impl.releaseCacheListeners
+	impl.indexMutex.Unlock()
+	for _, listener := range listeners {
+		listener.OnReleaseCacheUpdated(repository, releases)
+	}
+}
+
+// getCachedReleases reads releaseCache under indexMutex, it is written by setReleaseCache
+func (impl *ReleaseNoteServiceImpl) getCachedReleases(cacheKey string) []*common.Release {
+	impl.indexMutex.RLock()
+	defer impl.indexMutex.RUnlock()
+	return releaseCache[cacheKey]
+}
+
+// AddReleaseCacheListener registers a listener and replays the releases already in cache to it
+func (impl *ReleaseNoteServiceImpl) AddReleaseCacheListener(listener ReleaseCacheListener) {
+	impl.indexMutex.Lock()
+	impl.releaseCacheListeners = append(impl.releaseCacheListeners, listener)
+	cachedReleases := make(map[bean.Repository][]*common.Release)
+	for repo := range impl.repoCacheMap {
+		repository := bean.Repository(repo)
+		if releases, ok := releaseCache[bean.GetCacheKeyBasedOnRepo(repository)]; ok {
+			cachedReleases[repository] = releases
+		}
+	}
+	impl.indexMutex.Unlock()
+	for repository, releases := range cachedReleases {
+		listener.OnReleaseCacheUpdated(repository, releases)
+	}
+}
+
+func (impl *ReleaseNoteServiceImpl) getReleaseIndex(repository bean.Repository) (*releaseIndex, error) {
+	// keeps the cache in sync with the latest tag on blob before reading the index
+	_, err := impl.GetReleases(repository)
+	if err != nil {
+		return nil, err
+	}
+	impl.indexMutex.RLock()
+	defer impl.indexMutex.RUnlock()
+	index, ok := impl.releaseIndexMap[bean.GetCacheKeyBasedOnRepo(repository)]
+	if !ok {
+		return &releaseIndex{}, nil
+	}
+	return index, nil
+}
+
+func (impl *ReleaseNoteServiceImpl) GetReleaseByTag(repository bean.Repository, tagName string) (*common.Release, error) {
+	index, err := impl.getReleaseIndex(repository)
+	if err != nil {
+		return nil, err
+	}
+	if release, ok := index.releaseByTag[tagName]; ok {
+		return release, nil
+	}
+	// tags are looked up with and without the v prefix
+	if strings.HasPrefix(tagName, "v") {
+		return index.releaseByTag[strings.TrimPrefix(tagName, "v")], nil
+	}
+	return index.releaseByTag["v"+tagName], nil
+}
+
+func (impl *ReleaseNoteServiceImpl) GetLatestRelease(repository bean.Repository, channel string) (*common.Release, error) {
+	index, err := impl.getReleaseIndex(repository)
+	if err != nil {
+		return nil, err
+	}
+	return index.latestReleaseByChannel[channel], nil
+}
+
 func (impl *ReleaseNoteServiceImpl) updateTagToBlobStorage(releaseInfo *common.Release, repository bean.Repository) (bool, error) {
 	source, dest := getSrcAndDesForBlobBasedOnRepository(repository)
 	artifactUploaded := false
@@ -182,7 +393,8 @@ func (impl *ReleaseNoteServiceImpl) createFileAndUpdateDataForBlob(tagName strin
 func (impl *ReleaseNoteServiceImpl) GetReleasesFromGithub(repository bean.Repository) ([]*common.Release, bool) {
 	operationComplete := false
 	var releasesDto []*common.Release
-	releases, _, err := impl.client.GitHubClient.Repositories.ListReleases(context.Background(), impl.client.GitHubConfig.GitHubOrg, repository.String(), &github.ListOptions{})
+	githubRepository := impl.client.GetRepository(repository.String())
+	releases, _, err := githubRepository.Client.Repositories.ListReleases(context.Background(), githubRepository.Owner, githubRepository.Name, &github.ListOptions{})
 	if err != nil {
 		responseErr, ok := err.(*github.ErrorResponse)
 		if !ok || responseErr.Response.StatusCode != 404 {
@@ -205,6 +417,7 @@ func (impl *ReleaseNoteServiceImpl) GetReleasesFromGithub(repository bean.Reposi
 		}
 		var tagName, releaseName, body, tagLink string
 		var createdAt, publishedAt time.Time
+		var prerelease, draft bool
 		if item.TagName != nil {
 			tagName = *item.TagName
 		}
@@ -223,6 +436,23 @@ func (impl *ReleaseNoteServiceImpl) GetReleasesFromGithub(repository bean.Reposi
 		if item.PublishedAt != nil {
 			publishedAt = item.PublishedAt.Time
 		}
+		if item.Prerelease != nil {
+			prerelease = *item.Prerelease
+		}
+		if item.Draft != nil {
+			draft = *item.Draft
+		}
+		assets := make([]*common.ReleaseAsset, 0, len(item.Assets))
+		for _, asset := range item.Assets {
+			assets = append(assets, &common.ReleaseAsset{
+				Id:            asset.GetID(),
+				Name:          asset.GetName(),
+				Size:          asset.GetSize(),
+				DownloadUrl:   asset.GetBrowserDownloadURL(),
+				ContentType:   asset.GetContentType(),
+				DownloadCount: asset.GetDownloadCount(),
+			})
+		}
 		dto := &common.Release{
 			TagName:     tagName,
 			ReleaseName: releaseName,
@@ -230,8 +460,11 @@ func (impl *ReleaseNoteServiceImpl) GetReleasesFromGithub(repository bean.Reposi
 			PublishedAt: publishedAt,
 			Body:        body,
 			TagLink:     tagLink,
+			Prerelease:  prerelease,
+			Draft:       draft,
+			Assets:      assets,
 		}
-		impl.getPrerequisiteContent(dto)
+		impl.enrichRelease(repository, dto)
 		releasesDto = append(releasesDto, dto)
 	}
 
@@ -248,12 +481,13 @@ func (impl *ReleaseNoteServiceImpl) GetReleases(repository bean.Repository) ([]*
 		return releaseList, err
 	}
 	var tagNameFromCache string
-	if len(releaseCache) > 0 && len(releaseCache[cacheKey]) > 0 {
-		tagNameFromCache = releaseCache[cacheKey][0].TagName
+	cachedReleases := impl.getCachedReleases(cacheKey)
+	if len(cachedReleases) > 0 {
+		tagNameFromCache = cachedReleases[0].TagName
 	}
 	// if latest release tag is same with cache, return from cache
 	if tagNameFromCache == latestTagFromBlob {
-		return releaseCache[cacheKey], nil
+		return cachedReleases, nil
 	} else if tagNameFromCache != latestTagFromBlob {
 		// If tagName differ get it from github and update cache and upload to blob
 		releaseList, err = impl.GetReleasesFromGithubWithRetry(repository)
@@ -262,7 +496,7 @@ func (impl *ReleaseNoteServiceImpl) GetReleases(repository bean.Repository) ([]*
 		}
 		// Updating Cache and Updating tagName on blob
 		if len(releaseList) > 0 {
-			releaseCache[cacheKey] = releaseList
+			impl.setReleaseCache(repository, releaseList)
 			releaseInfo := releaseList[0]
 			_, err = impl.updateTagToBlobStorage(releaseInfo, repository)
 			if err != nil {
@@ -333,17 +567,101 @@ func (impl *ReleaseNoteServiceImpl) getLatestTagFromBlobStorage(repository bean.
 	return latestTagFromBlob, nil
 }
 
+// enrichRelease fills all the fields which are derived from release body and flags
+func (impl *ReleaseNoteServiceImpl) enrichRelease(repository bean.Repository, releaseInfo *common.Release) {
+	impl.getPrerequisiteContent(releaseInfo)
+	impl.getMandatoryUpgradeContent(releaseInfo)
+	releaseInfo.Sections = parseReleaseSections(releaseInfo.Body)
+	releaseInfo.BreakingChanges = parseMarkerBlockItems(releaseInfo.Body, bean.BreakingChangesMatcher)
+	releaseInfo.Deprecations = parseMarkerBlockItems(releaseInfo.Body, bean.DeprecationsMatcher)
+	impl.getReleaseMetadataContent(releaseInfo)
+	releaseInfo.Channels = impl.releaseChannelService.GetChannelsForRelease(repository, releaseInfo)
+}
+
 func (impl *ReleaseNoteServiceImpl) getPrerequisiteContent(releaseInfo *common.Release) {
-	if strings.Contains(releaseInfo.Body, bean.PrerequisitesMatcher) {
-		releaseInfo.Prerequisite = true
-		start := strings.Index(releaseInfo.Body, bean.PrerequisitesMatcher)
-		end := strings.LastIndex(releaseInfo.Body, bean.PrerequisitesMatcher)
-		if end == 0 {
-			return
+	hasMarker := strings.Contains(releaseInfo.Body, bean.PrerequisitesMatcher)
+	if !hasMarker && !strings.Contains(releaseInfo.Body, "```"+bean.PrerequisitesFenceInfo) {
+		return
+	}
+	releaseInfo.Prerequisite = true
+	prerequisites, validationErrors := parsePrerequisites(releaseInfo.Body)
+	if len(validationErrors) > 0 {
+		impl.logger.Warnw("malformed upgrade prerequisites in release body", "tagName", releaseInfo.TagName, "errors", validationErrors)
+	}
+	releaseInfo.Prerequisites = prerequisites
+	if !hasMarker {
+		var messages []string
+		for _, prerequisite := range prerequisites {
+			messages = append(messages, strings.TrimSpace(prerequisite.Title+"\n"+prerequisite.Body))
 		}
-		prerequisiteMessage := strings.ReplaceAll(releaseInfo.Body[start:end], bean.PrerequisitesMatcher, "")
-		releaseInfo.PrerequisiteMessage = prerequisiteMessage
+		releaseInfo.PrerequisiteMessage = strings.Join(messages, "\n\n")
+		return
 	}
+	start := strings.Index(releaseInfo.Body, bean.PrerequisitesMatcher)
+	end := strings.LastIndex(releaseInfo.Body, bean.PrerequisitesMatcher)
+	if end == start {
+		// closing marker is missing, take everything after the marker
+		end = len(releaseInfo.Body)
+	}
+	prerequisiteMessage := strings.ReplaceAll(releaseInfo.Body[start:end], bean.PrerequisitesMatcher, "")
+	releaseInfo.PrerequisiteMessage = prerequisiteMessage
+}
+
+func (impl *ReleaseNoteServiceImpl) ValidatePrerequisites(body string) *common.PrerequisiteValidationResult {
+	prerequisites, validationErrors := parsePrerequisites(body)
+	if prerequisites == nil {
+		prerequisites = make([]*common.Prerequisite, 0)
+	}
+	if validationErrors == nil {
+		validationErrors = make([]string, 0)
+	}
+	return &common.PrerequisiteValidationResult{
+		Valid:         len(validationErrors) == 0,
+		Prerequisites: prerequisites,
+		Errors:        validationErrors,
+	}
+}
+
+func (impl *ReleaseNoteServiceImpl) ValidateReleaseMetadata(body string) *common.ReleaseMetadataValidationResult {
+	compatibility, validationErrors, warnings := parseReleaseMetadata(body)
+	if validationErrors == nil {
+		validationErrors = make([]string, 0)
+	}
+	if warnings == nil {
+		warnings = make([]string, 0)
+	}
+	return &common.ReleaseMetadataValidationResult{
+		Valid:         len(validationErrors) == 0,
+		Compatibility: compatibility,
+		Errors:        validationErrors,
+		Warnings:      warnings,
+	}
+}
+
+// getReleaseMetadataContent sets the compatibility data of the release metadata, invalid metadata is dropped
+func (impl *ReleaseNoteServiceImpl) getReleaseMetadataContent(releaseInfo *common.Release) {
+	compatibility, validationErrors, warnings := parseReleaseMetadata(releaseInfo.Body)
+	if len(warnings) > 0 {
+		impl.logger.Warnw("release metadata of release body has warnings", "tagName", releaseInfo.TagName, "warnings", warnings)
+	}
+	if len(validationErrors) > 0 {
+		impl.logger.Warnw("invalid release metadata in release body", "tagName", releaseInfo.TagName, "errors", validationErrors)
+		return
+	}
+	releaseInfo.Compatibility = compatibility
+}
+
+func (impl *ReleaseNoteServiceImpl) MergeReleaseSections(releases []*common.Release) []*common.ReleaseSection {
+	return mergeReleaseSections(releases)
+}
+
+func (impl *ReleaseNoteServiceImpl) MergeReleaseNotices(releases []*common.Release) *common.BreakingChanges {
+	return mergeReleaseNotices(releases)
+}
+
+// getMandatoryUpgradeContent marks releases which can not be skipped while upgrading
+func (impl *ReleaseNoteServiceImpl) getMandatoryUpgradeContent(releaseInfo *common.Release) {
+	releaseInfo.MandatoryUpgrade = strings.Contains(releaseInfo.Body, bean.MandatoryUpgradeMatcher)
 }
 
 func (impl *ReleaseNoteServiceImpl) GetModules() ([]*common.Module, error) {
@@ -475,7 +793,6 @@ func (impl *ReleaseNoteServiceImpl) GetModuleByName(name string) (*common.Module
 }
 
 func (impl *ReleaseNoteServiceImpl) GetReleasesOnInitialisation(repository bean.Repository) error {
-	cacheKey := bean.GetCacheKeyBasedOnRepo(repository)
 	// Getting releases from github on Initialisation(will try 3 times if failed)
 	releases, err := impl.GetReleasesFromGithubWithRetry(repository)
 	if err != nil {
@@ -483,7 +800,7 @@ func (impl *ReleaseNoteServiceImpl) GetReleasesOnInitialisation(repository bean.
 		return err
 	}
 	if len(releases) > 0 {
-		releaseCache[cacheKey] = releases
+		impl.setReleaseCache(repository, releases)
 		releaseInfo := releases[0]
 		_, err = impl.updateTagToBlobStorage(releaseInfo, repository)
 		if err != nil {
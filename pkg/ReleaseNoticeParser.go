@@ -0,0 +1,85 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"github.com/devtron-labs/central-api/common"
+	"strings"
+)
+
+// parseMarkerBlockItems returns the items of all blocks enclosed by a pair of the marker. Every top level list
+// item of a block is an item, blocks without list items are a single item. Headings are skipped and a block
+// which is not closed is ignored.
+func parseMarkerBlockItems(body string, marker string) []*common.ChangelogItem {
+	items := make([]*common.ChangelogItem, 0)
+	blocks := strings.Split(body, marker)
+	// blocks at odd index are enclosed in markers
+	for i := 1; i+1 < len(blocks); i += 2 {
+		items = append(items, parseBlockItems(blocks[i])...)
+	}
+	return items
+}
+
+func parseBlockItems(block string) []*common.ChangelogItem {
+	var items []*common.ChangelogItem
+	var currentItem *common.ChangelogItem
+	var text []string
+	for _, line := range strings.Split(block, "\n") {
+		trimmedLine := strings.TrimSpace(line)
+		if len(trimmedLine) == 0 || changelogHeadingRegex.MatchString(trimmedLine) {
+			currentItem = nil
+			continue
+		}
+		isTopLevel := len(line) == len(strings.TrimLeft(line, " \t"))
+		if listItem := changelogListItemRegex.FindStringSubmatch(trimmedLine); listItem != nil && isTopLevel {
+			currentItem = &common.ChangelogItem{Text: listItem[2]}
+			items = append(items, currentItem)
+		} else if currentItem != nil {
+			// nested items and wrapped lines belong to the current item
+			currentItem.Text = currentItem.Text + "\n" + trimmedLine
+		} else {
+			text = append(text, trimmedLine)
+		}
+	}
+	if len(items) == 0 && len(text) > 0 {
+		items = append(items, &common.ChangelogItem{Text: strings.Join(text, "\n")})
+	}
+	return items
+}
+
+// mergeReleaseNotices collects the breaking changes and deprecations of the given releases, items keep the
+// order of the releases and carry the tag they were released in
+func mergeReleaseNotices(releases []*common.Release) *common.BreakingChanges {
+	breakingChanges := &common.BreakingChanges{
+		Releases:        make([]string, 0),
+		BreakingChanges: make([]*common.ChangelogItem, 0),
+		Deprecations:    make([]*common.ChangelogItem, 0),
+	}
+	for _, release := range releases {
+		if len(release.BreakingChanges) == 0 && len(release.Deprecations) == 0 {
+			continue
+		}
+		breakingChanges.Releases = append(breakingChanges.Releases, release.TagName)
+		for _, item := range release.BreakingChanges {
+			breakingChanges.BreakingChanges = append(breakingChanges.BreakingChanges, &common.ChangelogItem{Text: item.Text, TagName: release.TagName})
+		}
+		for _, item := range release.Deprecations {
+			breakingChanges.Deprecations = append(breakingChanges.Deprecations, &common.ChangelogItem{Text: item.Text, TagName: release.TagName})
+		}
+	}
+	return breakingChanges
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"fmt"
+	"github.com/devtron-labs/central-api/common"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"gopkg.in/yaml.v2"
+	"strings"
+)
+
+// parsePrerequisites reads structured prerequisites from a release body. Two formats are supported:
+//   - blocks enclosed by a pair of bean.PrerequisitesMatcher markers, an optional leading markdown heading is used as title
+//   - fenced code blocks with bean.PrerequisitesFenceInfo as info string holding a yaml list of prerequisites
+//
+// Malformed blocks are skipped and reported in the returned error messages.
+func parsePrerequisites(body string) ([]*common.Prerequisite, []string) {
+	var prerequisites []*common.Prerequisite
+	var validationErrors []string
+
+	fencedPrerequisites, fenceErrors := parseFencedPrerequisites(body)
+	validationErrors = append(validationErrors, fenceErrors...)
+
+	blocks := strings.Split(body, bean.PrerequisitesMatcher)
+	// blocks at odd index are enclosed in markers
+	markerCount := len(blocks) - 1
+	if markerCount%2 != 0 {
+		validationErrors = append(validationErrors, fmt.Sprintf("prerequisite marker %d is not closed", markerCount))
+	}
+	for i := 1; i+1 < len(blocks); i += 2 {
+		blockIndex := (i + 1) / 2
+		content := strings.TrimSpace(blocks[i])
+		if len(content) == 0 {
+			validationErrors = append(validationErrors, fmt.Sprintf("prerequisite block %d is empty", blockIndex))
+			continue
+		}
+		if strings.Contains(content, "```"+bean.PrerequisitesFenceInfo) {
+			// already parsed as fenced yaml
+			continue
+		}
+		prerequisite := &common.Prerequisite{
+			Id:       fmt.Sprintf("prerequisite-%d", blockIndex),
+			Severity: common.PrerequisiteSeverityWarning,
+			Body:     content,
+		}
+		lines := strings.SplitN(content, "\n", 2)
+		if strings.HasPrefix(lines[0], "#") {
+			prerequisite.Title = strings.TrimSpace(strings.TrimLeft(lines[0], "#"))
+			prerequisite.Body = ""
+			if len(lines) > 1 {
+				prerequisite.Body = strings.TrimSpace(lines[1])
+			}
+		}
+		prerequisites = append(prerequisites, prerequisite)
+	}
+	prerequisites = append(prerequisites, fencedPrerequisites...)
+
+	ids := make(map[string]bool)
+	for _, prerequisite := range prerequisites {
+		if ids[prerequisite.Id] {
+			validationErrors = append(validationErrors, fmt.Sprintf("duplicate prerequisite id %s", prerequisite.Id))
+		}
+		ids[prerequisite.Id] = true
+	}
+	return prerequisites, validationErrors
+}
+
+func parseFencedPrerequisites(body string) ([]*common.Prerequisite, []string) {
+	var prerequisites []*common.Prerequisite
+	var validationErrors []string
+	lines := strings.Split(body, "\n")
+	fenceCount := 0
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(line, "```") || strings.TrimSpace(strings.TrimPrefix(line, "```")) != bean.PrerequisitesFenceInfo {
+			continue
+		}
+		fenceCount++
+		var content []string
+		closed := false
+		for i = i + 1; i < len(lines); i++ {
+			if strings.TrimSpace(lines[i]) == "```" {
+				closed = true
+				break
+			}
+			content = append(content, lines[i])
+		}
+		if !closed {
+			validationErrors = append(validationErrors, fmt.Sprintf("prerequisite yaml block %d is not closed", fenceCount))
+			break
+		}
+		var items []*common.Prerequisite
+		err := yaml.Unmarshal([]byte(strings.Join(content, "\n")), &items)
+		if err != nil {
+			validationErrors = append(validationErrors, fmt.Sprintf("prerequisite yaml block %d is invalid: %s", fenceCount, err.Error()))
+			continue
+		}
+		for j, item := range items {
+			if item == nil {
+				continue
+			}
+			if len(item.Id) == 0 {
+				item.Id = fmt.Sprintf("prerequisite-yaml-%d-%d", fenceCount, j+1)
+			}
+			if len(item.Title) == 0 && len(item.Body) == 0 {
+				validationErrors = append(validationErrors, fmt.Sprintf("prerequisite %s has neither title nor body", item.Id))
+				continue
+			}
+			if len(item.Severity) == 0 {
+				item.Severity = common.PrerequisiteSeverityWarning
+			} else if !common.IsValidPrerequisiteSeverity(item.Severity) {
+				validationErrors = append(validationErrors, fmt.Sprintf("prerequisite %s has unknown severity %s", item.Id, item.Severity))
+				item.Severity = common.PrerequisiteSeverityWarning
+			}
+			prerequisites = append(prerequisites, item)
+		}
+	}
+	return prerequisites, validationErrors
+}
@@ -0,0 +1,337 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/devtron-labs/central-api/common"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"go.uber.org/zap"
+	"html"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+type ReleaseSearchService interface {
+	// Search returns the published releases of a repository matching all terms of the query, best match first.
+	// The last characters of a term may be omitted, terms match every indexed token they are a prefix of.
+	// Releases whose rollout does not include the installation yet are left out.
+	Search(repository bean.Repository, query string, versionConstraint *semver.Constraints, includePrerelease bool, size int, installationId string) ([]*common.ReleaseSearchResult, error)
+}
+
+const (
+	releaseSearchTitleWeight       = 3.0
+	releaseSearchBodyWeight        = 1.0
+	releaseSearchPrefixMatchWeight = 0.5
+	releaseSearchSnippetLength     = 200
+	releaseSearchSnippetContext    = 60
+)
+
+var releaseSearchStopWords = map[string]bool{
+	"an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true, "for": true, "from": true,
+	"in": true, "is": true, "it": true, "of": true, "on": true, "or": true, "the": true, "to": true, "with": true,
+}
+
+// releaseSearchIndex is an inverted index over the published releases of a repository
+type releaseSearchIndex struct {
+	releases []*common.Release
+	// postings holds the weighted term frequency of a token for every release position it occurs in
+	postings map[string]map[int]float64
+	// tokens are kept sorted for prefix lookups
+	tokens []string
+}
+
+type ReleaseSearchServiceImpl struct {
+	logger             *zap.SugaredLogger
+	releaseNoteService ReleaseNoteService
+	rolloutService     RolloutService
+	mutex              sync.RWMutex
+	indexMap           map[bean.Repository]*releaseSearchIndex
+}
+
+func NewReleaseSearchServiceImpl(logger *zap.SugaredLogger, releaseNoteService ReleaseNoteService, rolloutService RolloutService) *ReleaseSearchServiceImpl {
+	serviceImpl := &ReleaseSearchServiceImpl{
+		logger:             logger,
+		releaseNoteService: releaseNoteService,
+		rolloutService:     rolloutService,
+		indexMap:           make(map[bean.Repository]*releaseSearchIndex),
+	}
+	// index is rebuilt on every change of release cache, starting with the releases already cached
+	releaseNoteService.AddReleaseCacheListener(serviceImpl)
+	return serviceImpl
+}
+
+func (impl *ReleaseSearchServiceImpl) OnReleaseCacheUpdated(repository bean.Repository, releases []*common.Release) {
+	index := buildReleaseSearchIndex(releases)
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	impl.indexMap[repository] = index
+	impl.logger.Debugw("rebuilt release search index", "repository", repository, "releases", len(index.releases), "tokens", len(index.tokens))
+}
+
+func (impl *ReleaseSearchServiceImpl) Search(repository bean.Repository, query string, versionConstraint *semver.Constraints, includePrerelease bool, size int, installationId string) ([]*common.ReleaseSearchResult, error) {
+	// keeps release cache, and so the index, in sync with the latest release on blob
+	_, err := impl.releaseNoteService.GetReleases(repository)
+	if err != nil {
+		impl.logger.Errorw("error in getting releases for search", "repository", repository, "err", err)
+		return nil, err
+	}
+	impl.mutex.RLock()
+	index := impl.indexMap[repository]
+	impl.mutex.RUnlock()
+	results := make([]*common.ReleaseSearchResult, 0)
+	terms := tokenizeSearchText(query)
+	if index == nil || len(terms) == 0 {
+		return results, nil
+	}
+
+	scores := make(map[int]float64)
+	matchedTokens := make(map[int][]string)
+	for i, term := range terms {
+		termScores := make(map[int]float64)
+		for _, token := range index.getTokensWithPrefix(term) {
+			postings := index.postings[token]
+			matchWeight := 1.0
+			if token != term {
+				matchWeight = releaseSearchPrefixMatchWeight
+			}
+			idf := math.Log(1 + float64(len(index.releases))/float64(len(postings)))
+			for position, frequency := range postings {
+				termScores[position] += frequency * idf * matchWeight
+				matchedTokens[position] = append(matchedTokens[position], token)
+			}
+		}
+		// all terms need to match
+		for position := range scores {
+			if _, ok := termScores[position]; !ok {
+				delete(scores, position)
+			}
+		}
+		for position, score := range termScores {
+			if _, ok := scores[position]; ok || i == 0 {
+				scores[position] += score
+			}
+		}
+	}
+
+	for position, score := range scores {
+		release := index.releases[position]
+		if release.Prerelease && !includePrerelease {
+			continue
+		}
+		// filtered before results are cut to size, hidden releases would take the places of visible ones
+		if !impl.rolloutService.IsReleaseVisible(repository, release, installationId) {
+			continue
+		}
+		if versionConstraint != nil {
+			version, err := semver.NewVersion(release.TagName)
+			if err != nil || !versionConstraint.Check(version) {
+				continue
+			}
+		}
+		tokens := uniqueStrings(matchedTokens[position])
+		results = append(results, &common.ReleaseSearchResult{
+			TagName:      release.TagName,
+			ReleaseName:  release.ReleaseName,
+			PublishedAt:  release.PublishedAt,
+			TagLink:      release.TagLink,
+			Score:        math.Round(score*1000) / 1000,
+			Snippet:      getHighlightedSnippet(release.Body, tokens),
+			MatchedTerms: tokens,
+		})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		// newer releases first on equal score
+		if common.IsVersionNewer(results[i].TagName, results[j].TagName) {
+			return true
+		}
+		if common.IsVersionNewer(results[j].TagName, results[i].TagName) {
+			return false
+		}
+		return results[i].TagName > results[j].TagName
+	})
+	if size > 0 && len(results) > size {
+		results = results[:size]
+	}
+	return results, nil
+}
+
+func buildReleaseSearchIndex(releases []*common.Release) *releaseSearchIndex {
+	index := &releaseSearchIndex{
+		postings: make(map[string]map[int]float64),
+	}
+	for _, release := range releases {
+		if release.Draft {
+			continue
+		}
+		position := len(index.releases)
+		index.releases = append(index.releases, release)
+		index.addTokens(position, tokenizeSearchText(release.TagName+" "+release.ReleaseName), releaseSearchTitleWeight)
+		index.addTokens(position, tokenizeSearchText(release.Body), releaseSearchBodyWeight)
+	}
+	index.tokens = make([]string, 0, len(index.postings))
+	for token := range index.postings {
+		index.tokens = append(index.tokens, token)
+	}
+	sort.Strings(index.tokens)
+	return index
+}
+
+func (index *releaseSearchIndex) addTokens(position int, tokens []string, weight float64) {
+	for _, token := range tokens {
+		postings, ok := index.postings[token]
+		if !ok {
+			postings = make(map[int]float64)
+			index.postings[token] = postings
+		}
+		postings[position] += weight
+	}
+}
+
+func (index *releaseSearchIndex) getTokensWithPrefix(prefix string) []string {
+	var tokens []string
+	for i := sort.SearchStrings(index.tokens, prefix); i < len(index.tokens) && strings.HasPrefix(index.tokens[i], prefix); i++ {
+		tokens = append(tokens, index.tokens[i])
+	}
+	return tokens
+}
+
+// tokenizeSearchText lower cases text and splits it into words. Dots, dashes and underscores between letters or
+// digits are kept so that versions like v0.7.1 and flags like ENABLE_X stay searchable, the parts of such tokens
+// are indexed as well. Stop words and single characters are dropped.
+func tokenizeSearchText(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !isSearchTokenJoiner(r)
+	})
+	var tokens []string
+	for _, word := range words {
+		word = strings.TrimFunc(word, isSearchTokenJoiner)
+		tokens = appendSearchToken(tokens, word)
+		if strings.IndexFunc(word, isSearchTokenJoiner) < 0 {
+			continue
+		}
+		for _, part := range strings.FieldsFunc(word, isSearchTokenJoiner) {
+			tokens = appendSearchToken(tokens, part)
+		}
+	}
+	return tokens
+}
+
+func isSearchTokenJoiner(r rune) bool {
+	return r == '.' || r == '-' || r == '_'
+}
+
+func appendSearchToken(tokens []string, token string) []string {
+	if len([]rune(token)) < 2 || releaseSearchStopWords[token] {
+		return tokens
+	}
+	return append(tokens, token)
+}
+
+var markdownLinePrefixRegex = regexp.MustCompile(`^\s*(#{1,6}|>|[-*+]|[0-9]+[.)])\s+`)
+
+// getHighlightedSnippet returns html escaped text around the first occurrence of any of the tokens in body,
+// occurrences are wrapped in <mark> tags
+func getHighlightedSnippet(body string, tokens []string) string {
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = markdownLinePrefixRegex.ReplaceAllString(line, "")
+	}
+	text := strings.Join(strings.Fields(strings.Join(lines, " ")), " ")
+	if len(text) == 0 {
+		return ""
+	}
+	// longest tokens first so that alternation prefers them
+	sortedTokens := append([]string{}, tokens...)
+	sort.Slice(sortedTokens, func(i, j int) bool {
+		return len(sortedTokens[i]) > len(sortedTokens[j])
+	})
+	quotedTokens := make([]string, 0, len(sortedTokens))
+	for _, token := range sortedTokens {
+		quotedTokens = append(quotedTokens, regexp.QuoteMeta(token))
+	}
+	tokenRegex, err := regexp.Compile(`(?i)\b(?:` + strings.Join(quotedTokens, "|") + `)\b`)
+	if err != nil {
+		return html.EscapeString(truncateSnippet(text, 0, releaseSearchSnippetLength))
+	}
+	start := 0
+	if firstMatch := tokenRegex.FindStringIndex(text); firstMatch != nil && firstMatch[0] > releaseSearchSnippetContext {
+		start = firstMatch[0] - releaseSearchSnippetContext
+	}
+	window := truncateSnippet(text, start, start+releaseSearchSnippetLength)
+
+	var snippet strings.Builder
+	position := 0
+	for _, match := range tokenRegex.FindAllStringIndex(window, -1) {
+		snippet.WriteString(html.EscapeString(window[position:match[0]]))
+		snippet.WriteString("<mark>")
+		snippet.WriteString(html.EscapeString(window[match[0]:match[1]]))
+		snippet.WriteString("</mark>")
+		position = match[1]
+	}
+	snippet.WriteString(html.EscapeString(window[position:]))
+	return snippet.String()
+}
+
+// truncateSnippet cuts text to [start, end) on word boundaries and marks cut ends with an ellipsis
+func truncateSnippet(text string, start int, end int) string {
+	if end >= len(text) {
+		end = len(text)
+	} else if space := strings.LastIndex(text[:end], " "); space > start {
+		end = space
+	}
+	if start > 0 {
+		if space := strings.Index(text[start:end], " "); space >= 0 {
+			start = start + space + 1
+		}
+	}
+	// text without spaces may still be cut inside a multi byte character
+	for start > 0 && !utf8.RuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !utf8.RuneStart(text[end]) {
+		end++
+	}
+	snippet := text[start:end]
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(text) {
+		snippet = snippet + "…"
+	}
+	return snippet
+}
+
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	uniqueValues := make([]string, 0, len(values))
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			uniqueValues = append(uniqueValues, value)
+		}
+	}
+	return uniqueValues
+}
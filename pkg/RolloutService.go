@@ -0,0 +1,257 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"errors"
+	"fmt"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	internalUtil "github.com/devtron-labs/central-api/internal/util"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"go.uber.org/zap"
+	"sort"
+	"time"
+)
+
+// RolloutService manages the staged rollout of releases, releases without a rollout policy are announced to all
+// installations. Policies are persisted to ROLLOUT_POLICY_FILE.
+type RolloutService interface {
+	GetPolicies() []*common.RolloutPolicy
+	GetPolicy(repository bean.Repository, tagName string) (*common.RolloutPolicy, error)
+	// SavePolicy creates the policy of the release or replaces the existing one
+	SavePolicy(repository bean.Repository, tagName string, request *common.RolloutPolicyRequest) (*common.RolloutPolicy, error)
+	DeletePolicy(repository bean.Repository, tagName string) error
+	// AdvancePolicy moves the rollout to its next stage, a halted rollout is resumed at its current stage instead
+	AdvancePolicy(repository bean.Repository, tagName string) (*common.RolloutPolicy, error)
+	HaltPolicy(repository bean.Repository, tagName string) (*common.RolloutPolicy, error)
+	IsReleaseVisible(repository bean.Repository, release *common.Release, installationId string) bool
+	// FilterReleases drops the releases whose rollout does not include the installation yet
+	FilterReleases(repository bean.Repository, releases []*common.Release, installationId string) []*common.Release
+}
+
+type RolloutServiceImpl struct {
+	logger        *zap.SugaredLogger
+	rolloutConfig *util.RolloutConfig
+	// policies keyed on repository and tag, see getRolloutPolicyKey
+	policies *jsonFileStore[*common.RolloutPolicy]
+}
+
+// errRolloutPolicyExists stops the default rollout of a release which already has a policy
+var errRolloutPolicyExists = errors.New("rollout policy exists")
+
+func NewRolloutServiceImpl(logger *zap.SugaredLogger, rolloutConfig *util.RolloutConfig,
+	releaseNoteService ReleaseNoteService) (*RolloutServiceImpl, error) {
+	if len(rolloutConfig.DefaultStages) > 0 {
+		if err := validateRolloutStages(rolloutConfig.DefaultStages); err != nil {
+			logger.Errorw("invalid default rollout stages", "stages", rolloutConfig.DefaultStages, "err", err)
+			return nil, err
+		}
+	}
+	policies, err := newJsonFileStore(logger, "rollout policies", rolloutConfig.PolicyFile,
+		func(policy *common.RolloutPolicy) string {
+			return getRolloutPolicyKey(bean.Repository(policy.Repository), policy.TagName)
+		})
+	if err != nil {
+		return nil, err
+	}
+	serviceImpl := &RolloutServiceImpl{
+		logger:        logger,
+		rolloutConfig: rolloutConfig,
+		policies:      policies,
+	}
+	releaseNoteService.AddReleaseEventListener(serviceImpl)
+	return serviceImpl, nil
+}
+
+func getRolloutPolicyKey(repository bean.Repository, tagName string) string {
+	return fmt.Sprintf("%s/%s", repository, tagName)
+}
+
+// OnReleaseEvent starts the default rollout for newly published releases which have no policy yet
+func (impl *RolloutServiceImpl) OnReleaseEvent(event *common.ReleaseEvent) {
+	if event.Type != bean.ReleaseEventPublished || len(impl.rolloutConfig.DefaultStages) == 0 {
+		return
+	}
+	repository := bean.Repository(event.Repository)
+	key := getRolloutPolicyKey(repository, event.Release.TagName)
+	policy, err := impl.policies.Update(key, func(_ *common.RolloutPolicy, found bool) (*common.RolloutPolicy, error) {
+		if found {
+			return nil, errRolloutPolicyExists
+		}
+		now := time.Now()
+		policy := &common.RolloutPolicy{
+			Repository: repository.String(),
+			TagName:    event.Release.TagName,
+			Stages:     impl.rolloutConfig.DefaultStages,
+			CreatedOn:  now,
+			UpdatedOn:  now,
+		}
+		policy.Percentage = getRolloutPercentage(policy)
+		return policy, nil
+	})
+	if err != nil {
+		return
+	}
+	impl.logger.Infow("started default rollout of release", "repository", repository, "tagName", policy.TagName, "percentage", policy.Percentage)
+}
+
+func (impl *RolloutServiceImpl) GetPolicies() []*common.RolloutPolicy {
+	policies := impl.policies.List()
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].CreatedOn.Before(policies[j].CreatedOn)
+	})
+	return policies
+}
+
+func (impl *RolloutServiceImpl) GetPolicy(repository bean.Repository, tagName string) (*common.RolloutPolicy, error) {
+	policy, ok := impl.policies.Get(getRolloutPolicyKey(repository, tagName))
+	if !ok {
+		return nil, getRolloutPolicyNotFoundError(repository, tagName)
+	}
+	return policy, nil
+}
+
+func (impl *RolloutServiceImpl) SavePolicy(repository bean.Repository, tagName string, request *common.RolloutPolicyRequest) (*common.RolloutPolicy, error) {
+	if err := validateRolloutStages(request.Stages); err != nil {
+		return nil, err
+	}
+	if request.CurrentStage < 0 || request.CurrentStage >= len(request.Stages) {
+		return nil, internalUtil.NewBadRequestError(fmt.Sprintf("currentStage must be between 0 and %d", len(request.Stages)-1))
+	}
+	now := time.Now()
+	policy := &common.RolloutPolicy{
+		Repository:   repository.String(),
+		TagName:      tagName,
+		Stages:       request.Stages,
+		CurrentStage: request.CurrentStage,
+		CreatedOn:    now,
+		UpdatedOn:    now,
+	}
+	policy.Percentage = getRolloutPercentage(policy)
+	return impl.policies.Update(getRolloutPolicyKey(repository, tagName), func(existingPolicy *common.RolloutPolicy, found bool) (*common.RolloutPolicy, error) {
+		if found {
+			policy.CreatedOn = existingPolicy.CreatedOn
+		}
+		return policy, nil
+	})
+}
+
+func (impl *RolloutServiceImpl) DeletePolicy(repository bean.Repository, tagName string) error {
+	found, err := impl.policies.Delete(getRolloutPolicyKey(repository, tagName))
+	if err != nil {
+		return err
+	}
+	if !found {
+		return getRolloutPolicyNotFoundError(repository, tagName)
+	}
+	return nil
+}
+
+func (impl *RolloutServiceImpl) AdvancePolicy(repository bean.Repository, tagName string) (*common.RolloutPolicy, error) {
+	return impl.updatePolicy(repository, tagName, func(policy *common.RolloutPolicy) error {
+		if policy.Halted {
+			policy.Halted = false
+			return nil
+		}
+		if policy.CurrentStage+1 >= len(policy.Stages) {
+			return internalUtil.NewBadRequestError(fmt.Sprintf("rollout of %s is already at its last stage", tagName))
+		}
+		policy.CurrentStage++
+		return nil
+	})
+}
+
+func (impl *RolloutServiceImpl) HaltPolicy(repository bean.Repository, tagName string) (*common.RolloutPolicy, error) {
+	return impl.updatePolicy(repository, tagName, func(policy *common.RolloutPolicy) error {
+		policy.Halted = true
+		return nil
+	})
+}
+
+// updatePolicy applies the update to a copy of the policy, policies handed out are never modified
+func (impl *RolloutServiceImpl) updatePolicy(repository bean.Repository, tagName string, update func(policy *common.RolloutPolicy) error) (*common.RolloutPolicy, error) {
+	policy, err := impl.policies.Update(getRolloutPolicyKey(repository, tagName), func(existingPolicy *common.RolloutPolicy, found bool) (*common.RolloutPolicy, error) {
+		if !found {
+			return nil, getRolloutPolicyNotFoundError(repository, tagName)
+		}
+		policy := *existingPolicy
+		if err := update(&policy); err != nil {
+			return nil, err
+		}
+		policy.Percentage = getRolloutPercentage(&policy)
+		policy.UpdatedOn = time.Now()
+		return &policy, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	impl.logger.Infow("updated rollout of release", "repository", repository, "tagName", tagName, "percentage", policy.Percentage, "halted", policy.Halted)
+	return policy, nil
+}
+
+func (impl *RolloutServiceImpl) IsReleaseVisible(repository bean.Repository, release *common.Release, installationId string) bool {
+	policy, ok := impl.policies.Get(getRolloutPolicyKey(repository, release.TagName))
+	if !ok || policy.Percentage >= 100 {
+		return true
+	}
+	if len(installationId) == 0 {
+		// installations not telling their id only see fully rolled out releases
+		return false
+	}
+	return getRolloutBucket(getRolloutPolicyKey(repository, release.TagName), installationId) < policy.Percentage
+}
+
+func (impl *RolloutServiceImpl) FilterReleases(repository bean.Repository, releases []*common.Release, installationId string) []*common.Release {
+	var filteredReleases []*common.Release
+	for _, release := range releases {
+		if impl.IsReleaseVisible(repository, release, installationId) {
+			filteredReleases = append(filteredReleases, release)
+		}
+	}
+	return filteredReleases
+}
+
+func getRolloutPercentage(policy *common.RolloutPolicy) int {
+	if policy.Halted {
+		return 0
+	}
+	return policy.Stages[policy.CurrentStage]
+}
+
+// validateRolloutStages requires ascending percentages ending at 100 so that every rollout can complete
+func validateRolloutStages(stages []int) error {
+	if len(stages) == 0 {
+		return internalUtil.NewBadRequestError("stages are required")
+	}
+	for i, percentage := range stages {
+		if percentage < 0 || percentage > 100 {
+			return internalUtil.NewBadRequestError(fmt.Sprintf("stage percentage %d is out of 0-100", percentage))
+		}
+		if i > 0 && percentage <= stages[i-1] {
+			return internalUtil.NewBadRequestError("stage percentages must be ascending")
+		}
+	}
+	if stages[len(stages)-1] != 100 {
+		return internalUtil.NewBadRequestError("last stage must be 100")
+	}
+	return nil
+}
+
+func getRolloutPolicyNotFoundError(repository bean.Repository, tagName string) error {
+	return internalUtil.NewNotFoundError(fmt.Sprintf("rollout policy of %s %s not found", repository, tagName))
+}
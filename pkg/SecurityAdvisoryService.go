@@ -0,0 +1,343 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	internalUtil "github.com/devtron-labs/central-api/internal/util"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SecurityAdvisoryService serves the security advisories of repositories, either the published github repository
+// advisories or the ones of SECURITY_ADVISORIES_FILE
+type SecurityAdvisoryService interface {
+	// GetAdvisories returns the advisories of the repository newest first along with the release fixing each one,
+	// with a version only the advisories affecting that version are returned
+	GetAdvisories(repository bean.Repository, version string) (*common.SecurityAdvisories, error)
+}
+
+type securityAdvisoryCacheEntry struct {
+	advisories []*common.SecurityAdvisory
+	fetchedAt  time.Time
+}
+
+// securityAdvisoryFetch is a github fetch in progress, requests for the same repository wait on it instead
+// of fetching again
+type securityAdvisoryFetch struct {
+	done       chan struct{}
+	advisories []*common.SecurityAdvisory
+	err        error
+}
+
+type SecurityAdvisoryServiceImpl struct {
+	logger                 *zap.SugaredLogger
+	client                 *util.GitHubClient
+	securityAdvisoryConfig *util.SecurityAdvisoryConfig
+	releaseNoteService     ReleaseNoteService
+	// fileAdvisories are the advisories of the advisories file, nil when advisories are read from github
+	fileAdvisories []*common.SecurityAdvisory
+	cache          map[bean.Repository]*securityAdvisoryCacheEntry
+	fetches        map[bean.Repository]*securityAdvisoryFetch
+	// cacheLock guards cache and fetches, it is not held while fetching from github
+	cacheLock sync.Mutex
+}
+
+func NewSecurityAdvisoryServiceImpl(logger *zap.SugaredLogger, client *util.GitHubClient,
+	securityAdvisoryConfig *util.SecurityAdvisoryConfig, releaseNoteService ReleaseNoteService) (*SecurityAdvisoryServiceImpl, error) {
+	impl := &SecurityAdvisoryServiceImpl{
+		logger:                 logger,
+		client:                 client,
+		securityAdvisoryConfig: securityAdvisoryConfig,
+		releaseNoteService:     releaseNoteService,
+		cache:                  make(map[bean.Repository]*securityAdvisoryCacheEntry),
+		fetches:                make(map[bean.Repository]*securityAdvisoryFetch),
+	}
+	if len(securityAdvisoryConfig.AdvisoriesFile) > 0 {
+		advisories, err := loadSecurityAdvisoriesFile(securityAdvisoryConfig.AdvisoriesFile)
+		if err != nil {
+			logger.Errorw("error in loading security advisories file", "file", securityAdvisoryConfig.AdvisoriesFile, "err", err)
+			return nil, err
+		}
+		impl.fileAdvisories = advisories
+	}
+	return impl, nil
+}
+
+type securityAdvisoriesFile struct {
+	Advisories []*common.SecurityAdvisory `yaml:"advisories"`
+}
+
+// loadSecurityAdvisoriesFile reads the advisories listed under advisories, advisories without a repository
+// belong to the default repository
+func loadSecurityAdvisoriesFile(file string) ([]*common.SecurityAdvisory, error) {
+	content, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	advisoriesFile := &securityAdvisoriesFile{}
+	err = yaml.UnmarshalStrict(content, advisoriesFile)
+	if err != nil {
+		return nil, err
+	}
+	advisories := make([]*common.SecurityAdvisory, 0, len(advisoriesFile.Advisories))
+	for i, advisory := range advisoriesFile.Advisories {
+		if advisory == nil {
+			continue
+		}
+		if len(advisory.Id) == 0 {
+			return nil, fmt.Errorf("advisory %d has no id", i+1)
+		}
+		if _, err := semver.NewConstraint(advisory.VulnerableVersionRange); err != nil {
+			return nil, fmt.Errorf("advisory %s has invalid vulnerableVersionRange %s: %s", advisory.Id, advisory.VulnerableVersionRange, err.Error())
+		}
+		if len(advisory.Repository) == 0 {
+			advisory.Repository = bean.Oss.String()
+		}
+		if advisory.PatchedVersions == nil {
+			advisory.PatchedVersions = make([]string, 0)
+		}
+		advisories = append(advisories, advisory)
+	}
+	return advisories, nil
+}
+
+func (impl *SecurityAdvisoryServiceImpl) GetAdvisories(repository bean.Repository, version string) (*common.SecurityAdvisories, error) {
+	// checked before anything is fetched, github is called with the server token for any other repo
+	if !impl.client.IsConfiguredRepository(repository.String()) {
+		return nil, internalUtil.NewBadRequestError(fmt.Sprintf("unknown repo %s", repository))
+	}
+	var currentVersion *semver.Version
+	if len(version) > 0 {
+		var err error
+		currentVersion, err = semver.NewVersion(version)
+		if err != nil {
+			message := fmt.Sprintf("invalid version %s", version)
+			return nil, internalUtil.NewBadRequestError(message)
+		}
+	}
+	advisories, err := impl.getRepositoryAdvisories(repository)
+	if err != nil {
+		return nil, err
+	}
+	releases, err := impl.releaseNoteService.GetReleases(repository)
+	if err != nil {
+		impl.logger.Errorw("error in getting releases for security advisories", "repository", repository, "err", err)
+		return nil, err
+	}
+	releaseVersions := getPublishedReleaseVersions(releases)
+	result := &common.SecurityAdvisories{
+		Version:    version,
+		Advisories: make([]*common.SecurityAdvisory, 0),
+	}
+	for _, advisory := range advisories {
+		vulnerableVersions, err := semver.NewConstraint(advisory.VulnerableVersionRange)
+		if err != nil {
+			// not evaluated, only listed when no version is asked for
+			if currentVersion == nil {
+				result.Advisories = append(result.Advisories, advisory)
+			}
+			continue
+		}
+		if currentVersion != nil && !vulnerableVersions.Check(currentVersion) {
+			continue
+		}
+		// cached advisories are shared, the fixing release depends on the version
+		advisoryCopy := *advisory
+		advisoryCopy.FixedIn = getFixedInRelease(vulnerableVersions, releaseVersions, currentVersion)
+		result.Advisories = append(result.Advisories, &advisoryCopy)
+	}
+	sort.SliceStable(result.Advisories, func(i, j int) bool {
+		return result.Advisories[i].PublishedAt.After(result.Advisories[j].PublishedAt)
+	})
+	return result, nil
+}
+
+// getPublishedReleaseVersions returns the versions of the published non pre-releases, oldest first
+func getPublishedReleaseVersions(releases []*common.Release) []*semver.Version {
+	var versions []*semver.Version
+	for _, release := range FilterPublishedReleases(releases, false) {
+		if version, err := semver.NewVersion(release.TagName); err == nil {
+			versions = append(versions, version)
+		}
+	}
+	sort.Sort(semver.Collection(versions))
+	return versions
+}
+
+// getFixedInRelease returns the oldest release newer than currentVersion out of the vulnerable range. Without a
+// current version it is the oldest release out of the range newer than the oldest vulnerable release.
+func getFixedInRelease(vulnerableVersions *semver.Constraints, releaseVersions []*semver.Version, currentVersion *semver.Version) string {
+	affectedVersion := currentVersion
+	for _, version := range releaseVersions {
+		if affectedVersion == nil {
+			if vulnerableVersions.Check(version) {
+				affectedVersion = version
+			}
+			continue
+		}
+		if version.GreaterThan(affectedVersion) && !vulnerableVersions.Check(version) {
+			return version.Original()
+		}
+	}
+	return ""
+}
+
+func (impl *SecurityAdvisoryServiceImpl) getRepositoryAdvisories(repository bean.Repository) ([]*common.SecurityAdvisory, error) {
+	if impl.fileAdvisories != nil {
+		var advisories []*common.SecurityAdvisory
+		for _, advisory := range impl.fileAdvisories {
+			if advisory.Repository == repository.String() {
+				advisories = append(advisories, advisory)
+			}
+		}
+		return advisories, nil
+	}
+	impl.cacheLock.Lock()
+	cacheEntry, ok := impl.cache[repository]
+	ttl := time.Duration(impl.securityAdvisoryConfig.CacheTtlInMins) * time.Minute
+	if ok && time.Since(cacheEntry.fetchedAt) < ttl {
+		impl.cacheLock.Unlock()
+		return cacheEntry.advisories, nil
+	}
+	if fetch, fetching := impl.fetches[repository]; fetching {
+		impl.cacheLock.Unlock()
+		if ok {
+			// stale advisories are served while they are being refreshed
+			return cacheEntry.advisories, nil
+		}
+		<-fetch.done
+		return fetch.advisories, fetch.err
+	}
+	fetch := &securityAdvisoryFetch{done: make(chan struct{})}
+	impl.fetches[repository] = fetch
+	impl.cacheLock.Unlock()
+
+	fetch.advisories, fetch.err = impl.fetchAdvisories(repository)
+	impl.cacheLock.Lock()
+	delete(impl.fetches, repository)
+	if fetch.err == nil {
+		impl.cache[repository] = &securityAdvisoryCacheEntry{advisories: fetch.advisories, fetchedAt: time.Now()}
+	}
+	impl.cacheLock.Unlock()
+	close(fetch.done)
+	if fetch.err != nil {
+		impl.logger.Errorw("error in fetching security advisories from github", "repository", repository, "err", fetch.err)
+		if ok {
+			// stale advisories are better than none, fetch is retried on the next request
+			return cacheEntry.advisories, nil
+		}
+		return nil, fetch.err
+	}
+	return fetch.advisories, nil
+}
+
+type githubSecurityAdvisory struct {
+	GhsaId          string                         `json:"ghsa_id"`
+	CveId           *string                        `json:"cve_id"`
+	HtmlUrl         string                         `json:"html_url"`
+	Summary         string                         `json:"summary"`
+	Severity        string                         `json:"severity"`
+	PublishedAt     *time.Time                     `json:"published_at"`
+	WithdrawnAt     *time.Time                     `json:"withdrawn_at"`
+	Vulnerabilities []*githubAdvisoryVulnerability `json:"vulnerabilities"`
+}
+
+type githubAdvisoryVulnerability struct {
+	VulnerableVersionRange *string `json:"vulnerable_version_range"`
+	PatchedVersions        *string `json:"patched_versions"`
+}
+
+// githubNextPageRegex reads the next page url of a Link header, advisories are paginated by cursor
+var githubNextPageRegex = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// fetchAdvisories reads the published advisories of the repository, the vulnerable ranges of all
+// packages of an advisory are combined
+func (impl *SecurityAdvisoryServiceImpl) fetchAdvisories(repository bean.Repository) ([]*common.SecurityAdvisory, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(impl.securityAdvisoryConfig.FetchTimeoutInSecs)*time.Second)
+	defer cancel()
+	githubRepository := impl.client.GetRepository(repository.String())
+	advisories := make([]*common.SecurityAdvisory, 0)
+	pageUrl := fmt.Sprintf("repos/%s/%s/security-advisories?state=published&per_page=100", githubRepository.Owner, githubRepository.Name)
+	for len(pageUrl) > 0 {
+		request, err := githubRepository.Client.NewRequest(http.MethodGet, pageUrl, nil)
+		if err != nil {
+			return nil, err
+		}
+		var page []*githubSecurityAdvisory
+		response, err := githubRepository.Client.Do(ctx, request, &page)
+		if err != nil {
+			return nil, err
+		}
+		for _, githubAdvisory := range page {
+			if githubAdvisory.WithdrawnAt != nil {
+				continue
+			}
+			advisories = append(advisories, toSecurityAdvisory(repository, githubAdvisory))
+		}
+		pageUrl = ""
+		if nextPage := githubNextPageRegex.FindStringSubmatch(response.Header.Get("Link")); nextPage != nil {
+			pageUrl = nextPage[1]
+		}
+	}
+	return advisories, nil
+}
+
+func toSecurityAdvisory(repository bean.Repository, githubAdvisory *githubSecurityAdvisory) *common.SecurityAdvisory {
+	advisory := &common.SecurityAdvisory{
+		Id:              githubAdvisory.GhsaId,
+		Summary:         githubAdvisory.Summary,
+		Severity:        githubAdvisory.Severity,
+		Url:             githubAdvisory.HtmlUrl,
+		Repository:      repository.String(),
+		PatchedVersions: make([]string, 0),
+	}
+	if githubAdvisory.CveId != nil {
+		advisory.CveId = *githubAdvisory.CveId
+	}
+	if githubAdvisory.PublishedAt != nil {
+		advisory.PublishedAt = *githubAdvisory.PublishedAt
+	}
+	var vulnerableVersionRanges []string
+	for _, vulnerability := range githubAdvisory.Vulnerabilities {
+		if vulnerability.VulnerableVersionRange != nil && len(strings.TrimSpace(*vulnerability.VulnerableVersionRange)) > 0 {
+			vulnerableVersionRanges = append(vulnerableVersionRanges, strings.TrimSpace(*vulnerability.VulnerableVersionRange))
+		}
+		if vulnerability.PatchedVersions == nil {
+			continue
+		}
+		for _, patchedVersion := range strings.Split(*vulnerability.PatchedVersions, ",") {
+			if patchedVersion = strings.TrimSpace(patchedVersion); len(patchedVersion) > 0 {
+				advisory.PatchedVersions = append(advisory.PatchedVersions, patchedVersion)
+			}
+		}
+	}
+	advisory.VulnerableVersionRange = strings.Join(vulnerableVersionRanges, " || ")
+	return advisory
+}
@@ -0,0 +1,252 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	internalUtil "github.com/devtron-labs/central-api/internal/util"
+	"go.uber.org/zap"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TelemetryService ingests the heartbeats of installations and aggregates the installations active within a window
+type TelemetryService interface {
+	SaveHeartbeat(heartbeat *common.Heartbeat) error
+	GetVersionAdoption(windowInDays int) (*common.VersionAdoption, error)
+	GetModulePopularity(windowInDays int) (*common.ModulePopularity, error)
+}
+
+type heartbeatRateWindow struct {
+	start time.Time
+	count int
+}
+
+type TelemetryServiceImpl struct {
+	logger             *zap.SugaredLogger
+	telemetryConfig    *util.TelemetryConfig
+	telemetrySink      TelemetrySink
+	releaseNoteService ReleaseNoteService
+	rateLimitMutex     sync.Mutex
+	// heartbeats received per installation in the current hour
+	rateWindows map[string]*heartbeatRateWindow
+}
+
+func NewTelemetryServiceImpl(logger *zap.SugaredLogger, telemetryConfig *util.TelemetryConfig, telemetrySink TelemetrySink,
+	releaseNoteService ReleaseNoteService) *TelemetryServiceImpl {
+	serviceImpl := &TelemetryServiceImpl{
+		logger:             logger,
+		telemetryConfig:    telemetryConfig,
+		telemetrySink:      telemetrySink,
+		releaseNoteService: releaseNoteService,
+		rateWindows:        make(map[string]*heartbeatRateWindow),
+	}
+	go serviceImpl.pruneRateWindows()
+	return serviceImpl
+}
+
+var installationIdRegex = regexp.MustCompile(`^[A-Za-z0-9_-]{8,64}$`)
+
+// telemetryNameRegex restricts free text of heartbeats so that they can not carry identifying data
+var telemetryNameRegex = regexp.MustCompile(`^[a-z0-9-]{1,32}$`)
+
+const maxClusterNodeCount = 100000
+const maxTelemetryWindowInDays = 365
+
+func (impl *TelemetryServiceImpl) SaveHeartbeat(heartbeat *common.Heartbeat) error {
+	err := impl.validateHeartbeat(heartbeat)
+	if err != nil {
+		return err
+	}
+	err = impl.allowHeartbeat(heartbeat.InstallationId)
+	if err != nil {
+		return err
+	}
+	heartbeat.ReceivedAt = time.Now()
+	return impl.telemetrySink.Save(heartbeat)
+}
+
+func (impl *TelemetryServiceImpl) validateHeartbeat(heartbeat *common.Heartbeat) error {
+	if !installationIdRegex.MatchString(heartbeat.InstallationId) {
+		return internalUtil.NewBadRequestError("installationId must be 8 to 64 letters, digits, '-' or '_'")
+	}
+	if _, err := semver.NewVersion(heartbeat.Version); err != nil {
+		return internalUtil.NewBadRequestError(fmt.Sprintf("invalid version %s", heartbeat.Version))
+	}
+	heartbeat.Edition = strings.ToLower(heartbeat.Edition)
+	if len(heartbeat.Edition) > 0 && !telemetryNameRegex.MatchString(heartbeat.Edition) {
+		return internalUtil.NewBadRequestError(fmt.Sprintf("invalid edition %s", heartbeat.Edition))
+	}
+	modules, err := impl.releaseNoteService.GetModulesV2()
+	if err != nil {
+		return err
+	}
+	knownModules := make(map[string]bool, len(modules))
+	for _, module := range modules {
+		knownModules[module.Name] = true
+	}
+	reportedModules := make(map[string]bool, len(heartbeat.Modules))
+	for _, module := range heartbeat.Modules {
+		if module == nil {
+			return internalUtil.NewBadRequestError("modules must not be null")
+		}
+		if !knownModules[module.Name] {
+			return internalUtil.NewBadRequestError(fmt.Sprintf("unknown module %s, modules must be modules of /v2/modules", module.Name))
+		}
+		if reportedModules[module.Name] {
+			return internalUtil.NewBadRequestError(fmt.Sprintf("duplicate module %s", module.Name))
+		}
+		reportedModules[module.Name] = true
+		if _, err := semver.NewVersion(module.Version); len(module.Version) > 0 && err != nil {
+			return internalUtil.NewBadRequestError(fmt.Sprintf("invalid version %s of module %s", module.Version, module.Name))
+		}
+	}
+	if cluster := heartbeat.Cluster; cluster != nil {
+		if _, err := semver.NewVersion(cluster.KubernetesVersion); len(cluster.KubernetesVersion) > 0 && err != nil {
+			return internalUtil.NewBadRequestError(fmt.Sprintf("invalid kubernetesVersion %s", cluster.KubernetesVersion))
+		}
+		cluster.Provider = strings.ToLower(cluster.Provider)
+		if len(cluster.Provider) > 0 && !telemetryNameRegex.MatchString(cluster.Provider) {
+			return internalUtil.NewBadRequestError(fmt.Sprintf("invalid provider %s", cluster.Provider))
+		}
+		if cluster.NodeCount < 0 || cluster.NodeCount > maxClusterNodeCount {
+			return internalUtil.NewBadRequestError(fmt.Sprintf("nodeCount must be between 0 and %d", maxClusterNodeCount))
+		}
+	}
+	return nil
+}
+
+// allowHeartbeat counts the heartbeat against the hourly limit of the installation. Installations beyond
+// TELEMETRY_MAX_INSTALLATIONS are turned away until windows are pruned, so that random installation ids can not
+// grow the windows without bound.
+func (impl *TelemetryServiceImpl) allowHeartbeat(installationId string) error {
+	impl.rateLimitMutex.Lock()
+	defer impl.rateLimitMutex.Unlock()
+	now := time.Now()
+	rateWindow, ok := impl.rateWindows[installationId]
+	if !ok && len(impl.rateWindows) >= impl.telemetryConfig.MaxInstallations {
+		impl.logger.Warnw("heartbeat rejected, limit of installations reached", "maxInstallations", impl.telemetryConfig.MaxInstallations)
+		return internalUtil.NewApiError(http.StatusTooManyRequests, "too many installations reporting, retry later")
+	}
+	if !ok || now.Sub(rateWindow.start) >= time.Hour {
+		rateWindow = &heartbeatRateWindow{start: now}
+		impl.rateWindows[installationId] = rateWindow
+	}
+	if rateWindow.count >= impl.telemetryConfig.HeartbeatsPerHour {
+		message := fmt.Sprintf("heartbeat limit of %d per hour exceeded", impl.telemetryConfig.HeartbeatsPerHour)
+		return internalUtil.NewApiError(http.StatusTooManyRequests, message)
+	}
+	rateWindow.count++
+	return nil
+}
+
+// pruneRateWindows drops the windows of installations which stopped reporting
+func (impl *TelemetryServiceImpl) pruneRateWindows() {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+	for range ticker.C {
+		impl.rateLimitMutex.Lock()
+		for installationId, rateWindow := range impl.rateWindows {
+			if time.Since(rateWindow.start) >= time.Hour {
+				delete(impl.rateWindows, installationId)
+			}
+		}
+		impl.rateLimitMutex.Unlock()
+	}
+}
+
+func (impl *TelemetryServiceImpl) GetVersionAdoption(windowInDays int) (*common.VersionAdoption, error) {
+	heartbeats, windowInDays, err := impl.getActiveHeartbeats(windowInDays)
+	if err != nil {
+		return nil, err
+	}
+	installationsByVersion := make(map[string]int)
+	for _, heartbeat := range heartbeats {
+		installationsByVersion[heartbeat.Version]++
+	}
+	versions := getInstallationAggregates(installationsByVersion, len(heartbeats))
+	sort.SliceStable(versions, func(i, j int) bool {
+		return common.IsVersionNewer(versions[i].Name, versions[j].Name)
+	})
+	return &common.VersionAdoption{
+		WindowInDays:        windowInDays,
+		ActiveInstallations: len(heartbeats),
+		Versions:            versions,
+	}, nil
+}
+
+func (impl *TelemetryServiceImpl) GetModulePopularity(windowInDays int) (*common.ModulePopularity, error) {
+	heartbeats, windowInDays, err := impl.getActiveHeartbeats(windowInDays)
+	if err != nil {
+		return nil, err
+	}
+	installationsByModule := make(map[string]int)
+	for _, heartbeat := range heartbeats {
+		for _, module := range heartbeat.Modules {
+			installationsByModule[module.Name]++
+		}
+	}
+	modules := getInstallationAggregates(installationsByModule, len(heartbeats))
+	sort.SliceStable(modules, func(i, j int) bool {
+		return modules[i].Installations > modules[j].Installations
+	})
+	return &common.ModulePopularity{
+		WindowInDays:        windowInDays,
+		ActiveInstallations: len(heartbeats),
+		Modules:             modules,
+	}, nil
+}
+
+// getActiveHeartbeats returns the latest heartbeat of the installations active within the window, 0 uses
+// TELEMETRY_ACTIVE_WINDOW_IN_DAYS
+func (impl *TelemetryServiceImpl) getActiveHeartbeats(windowInDays int) ([]*common.Heartbeat, int, error) {
+	if windowInDays == 0 {
+		windowInDays = impl.telemetryConfig.ActiveWindowInDays
+	}
+	if windowInDays < 1 || windowInDays > maxTelemetryWindowInDays {
+		return nil, 0, internalUtil.NewBadRequestError(fmt.Sprintf("days must be between 1 and %d", maxTelemetryWindowInDays))
+	}
+	heartbeats, err := impl.telemetrySink.GetLatestHeartbeats(time.Now().AddDate(0, 0, -windowInDays))
+	if err != nil {
+		return nil, 0, err
+	}
+	return heartbeats, windowInDays, nil
+}
+
+// getInstallationAggregates returns the aggregates sorted by name, percentages are of all active installations
+func getInstallationAggregates(installationsByName map[string]int, activeInstallations int) []*common.InstallationAggregate {
+	aggregates := make([]*common.InstallationAggregate, 0, len(installationsByName))
+	for name, installations := range installationsByName {
+		aggregates = append(aggregates, &common.InstallationAggregate{
+			Name:          name,
+			Installations: installations,
+			Percentage:    math.Round(float64(installations)*10000/float64(activeInstallations)) / 100,
+		})
+	}
+	sort.Slice(aggregates, func(i, j int) bool {
+		return aggregates[i].Name < aggregates[j].Name
+	})
+	return aggregates
+}
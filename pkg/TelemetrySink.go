@@ -0,0 +1,288 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"github.com/go-pg/pg"
+	"github.com/golang/groupcache/lru"
+	"go.uber.org/zap"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TelemetrySink stores the heartbeats of installations, see TELEMETRY_SINK
+type TelemetrySink interface {
+	Save(heartbeat *common.Heartbeat) error
+	// GetLatestHeartbeats returns the latest heartbeat of every installation which reported since the given time
+	GetLatestHeartbeats(since time.Time) ([]*common.Heartbeat, error)
+}
+
+func NewTelemetrySink(logger *zap.SugaredLogger, telemetryConfig *util.TelemetryConfig) (TelemetrySink, error) {
+	switch telemetryConfig.Sink {
+	case bean.TelemetrySinkFile:
+		return NewFileTelemetrySinkImpl(logger, telemetryConfig)
+	case bean.TelemetrySinkPostgres:
+		return NewPostgresTelemetrySinkImpl(logger, telemetryConfig)
+	}
+	return nil, fmt.Errorf("unknown telemetry sink %s", telemetryConfig.Sink)
+}
+
+// telemetryFileMaxLineBytes bounds a line of the telemetry file independent of TELEMETRY_MAX_PAYLOAD_BYTES, which
+// may have been lowered after larger heartbeats were written
+const telemetryFileMaxLineBytes = 16 * 1024 * 1024
+
+// FileTelemetrySinkImpl appends heartbeats as json lines to TELEMETRY_FILE and keeps the latest heartbeat of every
+// installation in memory. The file is replayed on startup and compacted to these heartbeats periodically.
+type FileTelemetrySinkImpl struct {
+	logger          *zap.SugaredLogger
+	telemetryConfig *util.TelemetryConfig
+	mutex           sync.RWMutex
+	file            *os.File
+	// latestHeartbeats holds up to TELEMETRY_MAX_INSTALLATIONS installations, reportOrder evicts the ones which
+	// reported least recently beyond it
+	latestHeartbeats map[string]*common.Heartbeat
+	reportOrder      *lru.Cache
+}
+
+func NewFileTelemetrySinkImpl(logger *zap.SugaredLogger, telemetryConfig *util.TelemetryConfig) (*FileTelemetrySinkImpl, error) {
+	sinkImpl := &FileTelemetrySinkImpl{
+		logger:           logger,
+		telemetryConfig:  telemetryConfig,
+		latestHeartbeats: make(map[string]*common.Heartbeat),
+		reportOrder:      lru.New(telemetryConfig.MaxInstallations),
+	}
+	sinkImpl.reportOrder.OnEvicted = func(installationId lru.Key, _ interface{}) {
+		delete(sinkImpl.latestHeartbeats, installationId.(string))
+	}
+	if len(telemetryConfig.File) == 0 {
+		logger.Warnw("telemetry file is not configured, heartbeats are kept in memory only")
+		return sinkImpl, nil
+	}
+	err := sinkImpl.loadHeartbeats()
+	if err != nil {
+		logger.Errorw("error in reading telemetry file", "file", telemetryConfig.File, "err", err)
+		return nil, err
+	}
+	err = sinkImpl.compactFile()
+	if err != nil {
+		logger.Errorw("error in compacting telemetry file", "file", telemetryConfig.File, "err", err)
+		return nil, err
+	}
+	go sinkImpl.compactPeriodically()
+	return sinkImpl, nil
+}
+
+func (impl *FileTelemetrySinkImpl) loadHeartbeats() error {
+	file, err := os.Open(impl.telemetryConfig.File)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), telemetryFileMaxLineBytes)
+	for scanner.Scan() {
+		heartbeat := &common.Heartbeat{}
+		if err := json.Unmarshal(scanner.Bytes(), heartbeat); err != nil {
+			// a partially written last line of a crash
+			impl.logger.Warnw("skipping invalid line of telemetry file", "file", impl.telemetryConfig.File, "err", err)
+			continue
+		}
+		impl.setLatestHeartbeat(heartbeat)
+	}
+	return scanner.Err()
+}
+
+// setLatestHeartbeat keeps the heartbeat as the latest one of its installation, callers hold the write lock
+func (impl *FileTelemetrySinkImpl) setLatestHeartbeat(heartbeat *common.Heartbeat) {
+	impl.latestHeartbeats[heartbeat.InstallationId] = heartbeat
+	impl.reportOrder.Add(heartbeat.InstallationId, nil)
+}
+
+func (impl *FileTelemetrySinkImpl) compactPeriodically() {
+	ticker := time.NewTicker(time.Duration(impl.telemetryConfig.FileCompactionIntervalInMins) * time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		impl.mutex.Lock()
+		err := impl.compactFile()
+		impl.mutex.Unlock()
+		if err != nil {
+			impl.logger.Errorw("error in compacting telemetry file", "file", impl.telemetryConfig.File, "err", err)
+		}
+	}
+}
+
+// compactFile rewrites the file with the latest heartbeat of every installation, so that it does not grow with
+// every heartbeat received, and reopens it for appending. Callers hold the write lock.
+func (impl *FileTelemetrySinkImpl) compactFile() error {
+	heartbeats := make([]*common.Heartbeat, 0, len(impl.latestHeartbeats))
+	for _, heartbeat := range impl.latestHeartbeats {
+		heartbeats = append(heartbeats, heartbeat)
+	}
+	sort.Slice(heartbeats, func(i, j int) bool {
+		return heartbeats[i].ReceivedAt.Before(heartbeats[j].ReceivedAt)
+	})
+	var content bytes.Buffer
+	for _, heartbeat := range heartbeats {
+		line, err := json.Marshal(heartbeat)
+		if err != nil {
+			return err
+		}
+		content.Write(line)
+		content.WriteByte('\n')
+	}
+	err := writeFileAtomically(impl.telemetryConfig.File, content.Bytes())
+	if err != nil {
+		return err
+	}
+	// the previous file was replaced, heartbeats appended to it would be lost
+	file, err := os.OpenFile(impl.telemetryConfig.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if impl.file != nil {
+		impl.file.Close()
+	}
+	impl.file = file
+	return nil
+}
+
+func (impl *FileTelemetrySinkImpl) Save(heartbeat *common.Heartbeat) error {
+	content, err := json.Marshal(heartbeat)
+	if err != nil {
+		return err
+	}
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	if impl.file != nil {
+		if _, err = impl.file.Write(append(content, '\n')); err != nil {
+			impl.logger.Errorw("error in writing heartbeat to telemetry file", "installationId", heartbeat.InstallationId, "err", err)
+			return err
+		}
+	}
+	impl.setLatestHeartbeat(heartbeat)
+	return nil
+}
+
+func (impl *FileTelemetrySinkImpl) GetLatestHeartbeats(since time.Time) ([]*common.Heartbeat, error) {
+	impl.mutex.RLock()
+	defer impl.mutex.RUnlock()
+	heartbeats := make([]*common.Heartbeat, 0, len(impl.latestHeartbeats))
+	for _, heartbeat := range impl.latestHeartbeats {
+		if !heartbeat.ReceivedAt.Before(since) {
+			heartbeats = append(heartbeats, heartbeat)
+		}
+	}
+	return heartbeats, nil
+}
+
+// installationHeartbeat is a row of the installation_heartbeat table, modules and cluster are stored as jsonb
+type installationHeartbeat struct {
+	tableName      struct{}                  `sql:"installation_heartbeat"`
+	Id             int                       `sql:"id,pk"`
+	InstallationId string                    `sql:"installation_id,notnull"`
+	Version        string                    `sql:"version,notnull"`
+	Edition        string                    `sql:"edition"`
+	Modules        []*common.HeartbeatModule `sql:"modules"`
+	Cluster        *common.ClusterFacts      `sql:"cluster"`
+	ReceivedAt     time.Time                 `sql:"received_at,notnull"`
+}
+
+const installationHeartbeatTableQuery = `CREATE TABLE IF NOT EXISTS installation_heartbeat (
+	id              SERIAL PRIMARY KEY,
+	installation_id VARCHAR(64) NOT NULL,
+	version         VARCHAR(64) NOT NULL,
+	edition         VARCHAR(64),
+	modules         JSONB,
+	cluster         JSONB,
+	received_at     TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX IF NOT EXISTS installation_heartbeat_received_at_idx ON installation_heartbeat (received_at, installation_id);`
+
+// PostgresTelemetrySinkImpl inserts every heartbeat into the installation_heartbeat table, which is created on startup
+type PostgresTelemetrySinkImpl struct {
+	logger *zap.SugaredLogger
+	db     *pg.DB
+}
+
+func NewPostgresTelemetrySinkImpl(logger *zap.SugaredLogger, telemetryConfig *util.TelemetryConfig) (*PostgresTelemetrySinkImpl, error) {
+	db := pg.Connect(&pg.Options{
+		Addr:     telemetryConfig.PgAddr + ":" + telemetryConfig.PgPort,
+		User:     telemetryConfig.PgUser,
+		Password: telemetryConfig.PgPassword,
+		Database: telemetryConfig.PgDatabase,
+	})
+	_, err := db.Exec(installationHeartbeatTableQuery)
+	if err != nil {
+		logger.Errorw("error in creating installation heartbeat table", "addr", telemetryConfig.PgAddr, "database", telemetryConfig.PgDatabase, "err", err)
+		db.Close()
+		return nil, err
+	}
+	return &PostgresTelemetrySinkImpl{
+		logger: logger,
+		db:     db,
+	}, nil
+}
+
+func (impl *PostgresTelemetrySinkImpl) Save(heartbeat *common.Heartbeat) error {
+	row := &installationHeartbeat{
+		InstallationId: heartbeat.InstallationId,
+		Version:        heartbeat.Version,
+		Edition:        heartbeat.Edition,
+		Modules:        heartbeat.Modules,
+		Cluster:        heartbeat.Cluster,
+		ReceivedAt:     heartbeat.ReceivedAt,
+	}
+	err := impl.db.Insert(row)
+	if err != nil {
+		impl.logger.Errorw("error in inserting heartbeat", "installationId", heartbeat.InstallationId, "err", err)
+	}
+	return err
+}
+
+func (impl *PostgresTelemetrySinkImpl) GetLatestHeartbeats(since time.Time) ([]*common.Heartbeat, error) {
+	var rows []*installationHeartbeat
+	query := `SELECT DISTINCT ON (installation_id) * FROM installation_heartbeat WHERE received_at >= ?
+		ORDER BY installation_id, received_at DESC`
+	_, err := impl.db.Query(&rows, query, since)
+	if err != nil {
+		impl.logger.Errorw("error in getting latest heartbeats", "since", since, "err", err)
+		return nil, err
+	}
+	heartbeats := make([]*common.Heartbeat, 0, len(rows))
+	for _, row := range rows {
+		heartbeats = append(heartbeats, &common.Heartbeat{
+			InstallationId: row.InstallationId,
+			Version:        row.Version,
+			Edition:        row.Edition,
+			Modules:        row.Modules,
+			Cluster:        row.Cluster,
+			ReceivedAt:     row.ReceivedAt,
+		})
+	}
+	return heartbeats, nil
+}
@@ -0,0 +1,177 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/devtron-labs/central-api/common"
+	internalUtil "github.com/devtron-labs/central-api/internal/util"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"go.uber.org/zap"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// UpgradeCheckService tells an installation if there is a newer release to upgrade to and how urgent it is
+type UpgradeCheckService interface {
+	CheckUpgrade(repository bean.Repository, request *common.UpgradeCheckRequest) (*common.UpgradeCheck, error)
+}
+
+type UpgradeCheckServiceImpl struct {
+	logger                *zap.SugaredLogger
+	releaseNoteService    ReleaseNoteService
+	releaseChannelService ReleaseChannelService
+	rolloutService        RolloutService
+}
+
+func NewUpgradeCheckServiceImpl(logger *zap.SugaredLogger, releaseNoteService ReleaseNoteService,
+	releaseChannelService ReleaseChannelService, rolloutService RolloutService) *UpgradeCheckServiceImpl {
+	return &UpgradeCheckServiceImpl{
+		logger:                logger,
+		releaseNoteService:    releaseNoteService,
+		releaseChannelService: releaseChannelService,
+		rolloutService:        rolloutService,
+	}
+}
+
+// securityFixRegex matches changelog items and section titles of security fixes
+var securityFixRegex = regexp.MustCompile(`(?i)\b(security|vulnerabilit(y|ies)|CVE-[0-9]{4}-[0-9]+)\b`)
+
+func (impl *UpgradeCheckServiceImpl) CheckUpgrade(repository bean.Repository, request *common.UpgradeCheckRequest) (*common.UpgradeCheck, error) {
+	currentVersion, err := semver.NewVersion(request.CurrentVersion)
+	if err != nil {
+		return nil, internalUtil.NewBadRequestError(fmt.Sprintf("invalid currentVersion %s", request.CurrentVersion))
+	}
+	if len(request.Channel) > 0 && !impl.releaseChannelService.IsValidChannel(repository, request.Channel) {
+		return nil, internalUtil.NewBadRequestError(fmt.Sprintf("unknown channel %s", request.Channel))
+	}
+	for module, version := range request.Modules {
+		if _, err := semver.NewVersion(version); len(version) > 0 && err != nil {
+			return nil, internalUtil.NewBadRequestError(fmt.Sprintf("invalid version %s of module %s", version, module))
+		}
+	}
+	releases, err := impl.releaseNoteService.GetReleases(repository)
+	if err != nil {
+		impl.logger.Errorw("error in getting releases for upgrade check", "repository", repository, "err", err)
+		return nil, err
+	}
+	releases, _ = FilterReleases(releases, &ReleaseFilter{
+		Channel:           request.Channel,
+		IncludePrerelease: request.IncludePrerelease,
+	})
+	upgradeCheck := &common.UpgradeCheck{
+		CurrentVersion:     request.CurrentVersion,
+		LatestVersion:      request.CurrentVersion,
+		PendingReleases:    make([]string, 0),
+		ModuleRequirements: make([]*common.ModuleRequirement, 0),
+	}
+	// releases are sorted newest first
+	for _, release := range releases {
+		version, err := semver.NewVersion(release.TagName)
+		if err != nil {
+			continue
+		}
+		if !version.GreaterThan(currentVersion) {
+			if version.Equal(currentVersion) && release.Compatibility != nil {
+				upgradeCheck.EndOfLifeReached = IsEndOfLifeReached(release.Compatibility, time.Now())
+			}
+			continue
+		}
+		if !impl.rolloutService.IsReleaseVisible(repository, release, request.InstallationId) {
+			continue
+		}
+		if !upgradeCheck.UpgradeAvailable {
+			upgradeCheck.UpgradeAvailable = true
+			upgradeCheck.LatestVersion = release.TagName
+		}
+		upgradeCheck.PendingReleases = append(upgradeCheck.PendingReleases, release.TagName)
+		upgradeCheck.PrerequisitePending = upgradeCheck.PrerequisitePending || release.Prerequisite
+		upgradeCheck.MandatoryUpgradePending = upgradeCheck.MandatoryUpgradePending || release.MandatoryUpgrade
+		upgradeCheck.SecurityFixPending = upgradeCheck.SecurityFixPending || hasSecurityFix(release)
+		upgradeCheck.ModuleRequirements = append(upgradeCheck.ModuleRequirements, getUnmetModuleRequirements(release, request.Modules)...)
+	}
+	upgradeCheck.ReleasesBehind = len(upgradeCheck.PendingReleases)
+	if len(upgradeCheck.ModuleRequirements) > 0 {
+		upgradeCheck.PrerequisitePending = true
+	}
+	upgradeCheck.Severity = getUpgradeSeverity(upgradeCheck, currentVersion)
+	return upgradeCheck, nil
+}
+
+func hasSecurityFix(release *common.Release) bool {
+	for _, section := range release.Sections {
+		if securityFixRegex.MatchString(section.Title) {
+			return true
+		}
+		for _, item := range section.Items {
+			if securityFixRegex.MatchString(item.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getUnmetModuleRequirements returns the minimum module versions of the release which installed modules do not
+// meet, modules installed without a version are not checked
+func getUnmetModuleRequirements(release *common.Release, modules map[string]string) []*common.ModuleRequirement {
+	var requirements []*common.ModuleRequirement
+	if release.Compatibility == nil {
+		return requirements
+	}
+	moduleNames := make([]string, 0, len(release.Compatibility.MinModuleVersions))
+	for module := range release.Compatibility.MinModuleVersions {
+		moduleNames = append(moduleNames, module)
+	}
+	sort.Strings(moduleNames)
+	for _, module := range moduleNames {
+		minVersion := release.Compatibility.MinModuleVersions[module]
+		installedVersion, ok := modules[module]
+		if !ok || len(installedVersion) == 0 {
+			continue
+		}
+		if common.IsVersionNewer(minVersion, installedVersion) {
+			requirements = append(requirements, &common.ModuleRequirement{
+				Module:           module,
+				InstalledVersion: installedVersion,
+				MinVersion:       minVersion,
+				TagName:          release.TagName,
+			})
+		}
+	}
+	return requirements
+}
+
+// getUpgradeSeverity is critical for pending security fixes, high for mandatory upgrades or an installation
+// past end of life, medium when a newer minor or major version is out and low for patches only
+func getUpgradeSeverity(upgradeCheck *common.UpgradeCheck, currentVersion *semver.Version) string {
+	switch {
+	case !upgradeCheck.UpgradeAvailable && !upgradeCheck.EndOfLifeReached:
+		return bean.UpgradeSeverityNone
+	case upgradeCheck.SecurityFixPending:
+		return bean.UpgradeSeverityCritical
+	case upgradeCheck.MandatoryUpgradePending || upgradeCheck.EndOfLifeReached:
+		return bean.UpgradeSeverityHigh
+	}
+	latestVersion, err := semver.NewVersion(upgradeCheck.LatestVersion)
+	if err == nil && (latestVersion.Major() != currentVersion.Major() || latestVersion.Minor() != currentVersion.Minor()) {
+		return bean.UpgradeSeverityMedium
+	}
+	return bean.UpgradeSeverityLow
+}
@@ -0,0 +1,263 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"context"
+	"fmt"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"github.com/google/go-github/github"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	githubHookName        = "web"
+	githubHookContentType = "json"
+)
+
+// WebhookRegistrationService makes sure every configured repository has a github release webhook pointing at
+// central api with the configured secret, missing webhooks are created and drifted ones repaired
+type WebhookRegistrationService interface {
+	GetStatus() *common.WebhookRegistrationStatus
+	// Sync checks and repairs the webhooks of all repositories and returns the resulting status
+	Sync() *common.WebhookRegistrationStatus
+}
+
+type WebhookRegistrationServiceImpl struct {
+	logger                    *zap.SugaredLogger
+	client                    *util.GitHubClient
+	webhookRegistrationConfig *util.WebhookRegistrationConfig
+	// syncMutex keeps concurrent syncs from creating duplicate webhooks
+	syncMutex sync.Mutex
+	mutex     sync.RWMutex
+	status    *common.WebhookRegistrationStatus
+	// secretAppliedHooks holds the ids of webhooks the secret was written to by this process, github masks the
+	// secret so a rotated one is only known to be in place once it has been written. Guarded by syncMutex.
+	secretAppliedHooks map[int64]bool
+}
+
+func NewWebhookRegistrationServiceImpl(logger *zap.SugaredLogger, client *util.GitHubClient,
+	webhookRegistrationConfig *util.WebhookRegistrationConfig) (*WebhookRegistrationServiceImpl, error) {
+	serviceImpl := &WebhookRegistrationServiceImpl{
+		logger:                    logger,
+		client:                    client,
+		webhookRegistrationConfig: webhookRegistrationConfig,
+		secretAppliedHooks:        make(map[int64]bool),
+	}
+	status := &common.WebhookRegistrationStatus{
+		Enabled:      webhookRegistrationConfig.Enabled,
+		PublicUrl:    webhookRegistrationConfig.PublicUrl,
+		Repositories: make([]*common.RepositoryWebhookStatus, 0),
+	}
+	if webhookRegistrationConfig.Enabled {
+		if len(webhookRegistrationConfig.PublicUrl) == 0 {
+			return nil, fmt.Errorf("GITHUB_WEBHOOK_PUBLIC_URL is required when webhook registration is enabled")
+		}
+		if len(client.GitHubConfig.GitHubWebhookSecret) == 0 {
+			return nil, fmt.Errorf("GITHUB_WEBHOOK_SECRET is required when webhook registration is enabled")
+		}
+		if webhookRegistrationConfig.TimeoutInSecs <= 0 {
+			return nil, fmt.Errorf("GITHUB_WEBHOOK_REGISTRATION_TIMEOUT_IN_SECS should be positive, found %d", webhookRegistrationConfig.TimeoutInSecs)
+		}
+		for _, repository := range client.Repositories {
+			status.Repositories = append(status.Repositories, &common.RepositoryWebhookStatus{
+				Repository: repository.Alias,
+				State:      bean.WebhookRegistrationPending,
+			})
+		}
+	}
+	serviceImpl.status = status
+	if webhookRegistrationConfig.Enabled {
+		// github is not waited on, startup does not depend on its availability
+		go serviceImpl.syncPeriodically()
+	}
+	return serviceImpl, nil
+}
+
+func (impl *WebhookRegistrationServiceImpl) GetStatus() *common.WebhookRegistrationStatus {
+	impl.mutex.RLock()
+	defer impl.mutex.RUnlock()
+	return impl.status
+}
+
+func (impl *WebhookRegistrationServiceImpl) syncPeriodically() {
+	impl.Sync()
+	if impl.webhookRegistrationConfig.SyncIntervalInMins <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Duration(impl.webhookRegistrationConfig.SyncIntervalInMins) * time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		impl.Sync()
+	}
+}
+
+func (impl *WebhookRegistrationServiceImpl) Sync() *common.WebhookRegistrationStatus {
+	if !impl.webhookRegistrationConfig.Enabled {
+		return impl.GetStatus()
+	}
+	impl.syncMutex.Lock()
+	defer impl.syncMutex.Unlock()
+	repositories := make([]*common.RepositoryWebhookStatus, 0, len(impl.client.Repositories))
+	for _, repository := range impl.client.Repositories {
+		repositoryStatus := impl.syncRepository(repository)
+		if repositoryStatus.State == bean.WebhookRegistrationFailed {
+			impl.logger.Errorw("error in registering github webhook", "repo", repository.Alias, "err", repositoryStatus.Error)
+		} else {
+			impl.logger.Infow("github webhook checked", "repo", repository.Alias, "hookId", repositoryStatus.HookId, "state", repositoryStatus.State, "drift", repositoryStatus.Drift)
+		}
+		repositories = append(repositories, repositoryStatus)
+	}
+	syncedOn := time.Now()
+	status := &common.WebhookRegistrationStatus{
+		Enabled:      true,
+		PublicUrl:    impl.webhookRegistrationConfig.PublicUrl,
+		LastSyncedOn: &syncedOn,
+		Repositories: repositories,
+	}
+	impl.mutex.Lock()
+	impl.status = status
+	impl.mutex.Unlock()
+	return status
+}
+
+func (impl *WebhookRegistrationServiceImpl) syncRepository(repository *util.GitHubRepository) *common.RepositoryWebhookStatus {
+	checkedOn := time.Now()
+	status := &common.RepositoryWebhookStatus{
+		Repository: repository.Alias,
+		CheckedOn:  &checkedOn,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(impl.webhookRegistrationConfig.TimeoutInSecs)*time.Second)
+	defer cancel()
+	hook, err := impl.findHook(ctx, repository)
+	if err != nil {
+		status.State = bean.WebhookRegistrationFailed
+		status.Error = err.Error()
+		return status
+	}
+	if hook == nil {
+		createdHook, _, err := repository.Client.Repositories.CreateHook(ctx, repository.Owner, repository.Name, impl.getDesiredHook(nil))
+		if err != nil {
+			status.State = bean.WebhookRegistrationFailed
+			status.Error = err.Error()
+			return status
+		}
+		status.HookId = createdHook.GetID()
+		status.State = bean.WebhookRegistrationCreated
+		impl.secretAppliedHooks[createdHook.GetID()] = true
+		return status
+	}
+	status.HookId = hook.GetID()
+	status.Drift = impl.getHookDrift(hook)
+	if !impl.secretAppliedHooks[hook.GetID()] {
+		status.Drift = append(status.Drift, "secret is not known to match GITHUB_WEBHOOK_SECRET")
+	}
+	if len(status.Drift) == 0 {
+		status.State = bean.WebhookRegistrationInSync
+		return status
+	}
+	_, _, err = repository.Client.Repositories.EditHook(ctx, repository.Owner, repository.Name, hook.GetID(), impl.getDesiredHook(hook))
+	if err != nil {
+		status.State = bean.WebhookRegistrationFailed
+		status.Error = err.Error()
+		return status
+	}
+	impl.secretAppliedHooks[hook.GetID()] = true
+	status.State = bean.WebhookRegistrationUpdated
+	return status
+}
+
+// findHook returns the webhook of the repository delivering to the public url, nil if there is none
+func (impl *WebhookRegistrationServiceImpl) findHook(ctx context.Context, repository *util.GitHubRepository) (*github.Hook, error) {
+	listOptions := &github.ListOptions{PerPage: 100}
+	for {
+		hooks, response, err := repository.Client.Repositories.ListHooks(ctx, repository.Owner, repository.Name, listOptions)
+		if err != nil {
+			return nil, err
+		}
+		for _, hook := range hooks {
+			hookUrl, _ := hook.Config["url"].(string)
+			if isSameWebhookUrl(hookUrl, impl.webhookRegistrationConfig.PublicUrl) {
+				return hook, nil
+			}
+		}
+		if response.NextPage == 0 {
+			return nil, nil
+		}
+		listOptions.Page = response.NextPage
+	}
+}
+
+// getHookDrift lists the differences of a webhook from the desired one. The secret is masked by github so
+// only a missing secret is detected here, a different one is repaired by writing the secret once per process,
+// see secretAppliedHooks.
+func (impl *WebhookRegistrationServiceImpl) getHookDrift(hook *github.Hook) []string {
+	var drift []string
+	if !hook.GetActive() {
+		drift = append(drift, "webhook is inactive")
+	}
+	if contentType, _ := hook.Config["content_type"].(string); contentType != githubHookContentType {
+		drift = append(drift, fmt.Sprintf("content type is %q instead of %q", contentType, githubHookContentType))
+	}
+	if insecureSsl := fmt.Sprint(hook.Config["insecure_ssl"]); insecureSsl != "0" && insecureSsl != "<nil>" {
+		drift = append(drift, "ssl verification is disabled")
+	}
+	if secret, _ := hook.Config["secret"].(string); len(secret) == 0 {
+		drift = append(drift, "secret is not set")
+	}
+	if !containsString(hook.Events, bean.EventTypeRelease) && !containsString(hook.Events, "*") {
+		drift = append(drift, fmt.Sprintf("%s events are not subscribed", bean.EventTypeRelease))
+	}
+	return drift
+}
+
+// getDesiredHook returns the webhook to be created, or the repaired one keeping the other events of an existing hook
+func (impl *WebhookRegistrationServiceImpl) getDesiredHook(existingHook *github.Hook) *github.Hook {
+	events := []string{bean.EventTypeRelease}
+	if existingHook != nil {
+		for _, event := range existingHook.Events {
+			if !containsString(events, event) {
+				events = append(events, event)
+			}
+		}
+	}
+	active := true
+	hook := &github.Hook{
+		Active: &active,
+		Events: events,
+		Config: map[string]interface{}{
+			"url":          impl.webhookRegistrationConfig.PublicUrl,
+			"content_type": githubHookContentType,
+			"secret":       impl.client.GitHubConfig.GitHubWebhookSecret,
+			"insecure_ssl": "0",
+		},
+	}
+	if existingHook == nil {
+		name := githubHookName
+		hook.Name = &name
+	}
+	return hook
+}
+
+func isSameWebhookUrl(hookUrl string, publicUrl string) bool {
+	return len(hookUrl) > 0 && strings.TrimSuffix(hookUrl, "/") == strings.TrimSuffix(publicUrl, "/")
+}
@@ -0,0 +1,358 @@
+/*
+ * Copyright (c) 2024. Devtron Inc.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pkg
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	util "github.com/devtron-labs/central-api/client"
+	"github.com/devtron-labs/central-api/common"
+	internalUtil "github.com/devtron-labs/central-api/internal/util"
+	"github.com/devtron-labs/central-api/pkg/bean"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	WebhookEventHeader     = "X-Devtron-Event"
+	WebhookDeliveryHeader  = "X-Devtron-Delivery"
+	WebhookSignatureHeader = "X-Devtron-Signature-256"
+)
+
+type WebhookSubscriptionService interface {
+	GetSubscriptions() []*common.WebhookSubscription
+	GetSubscription(id string) (*common.WebhookSubscription, error)
+	CreateSubscription(request *common.WebhookSubscriptionRequest) (*common.WebhookSubscription, error)
+	UpdateSubscription(id string, request *common.WebhookSubscriptionRequest) (*common.WebhookSubscription, error)
+	DeleteSubscription(id string) error
+	// GetDeliveries returns the latest delivery attempts of a subscription, newest first
+	GetDeliveries(id string) ([]*common.WebhookDelivery, error)
+}
+
+type webhookDeliveryJob struct {
+	deliveryId     string
+	subscriptionId string
+	event          *common.ReleaseEvent
+	payload        []byte
+	attempt        int
+}
+
+type WebhookSubscriptionServiceImpl struct {
+	logger                    *zap.SugaredLogger
+	webhookSubscriptionConfig *util.WebhookSubscriptionConfig
+	httpClient                *http.Client
+	subscriptions             *jsonFileStore[*common.WebhookSubscription]
+	// mutex guards deliveries
+	mutex         sync.RWMutex
+	deliveries    map[string][]*common.WebhookDelivery
+	deliveryQueue chan *webhookDeliveryJob
+}
+
+func NewWebhookSubscriptionServiceImpl(logger *zap.SugaredLogger, webhookSubscriptionConfig *util.WebhookSubscriptionConfig,
+	releaseNoteService ReleaseNoteService) (*WebhookSubscriptionServiceImpl, error) {
+	subscriptions, err := newJsonFileStore(logger, "webhook subscriptions", webhookSubscriptionConfig.SubscriptionFile,
+		func(subscription *common.WebhookSubscription) string {
+			return subscription.Id
+		})
+	if err != nil {
+		return nil, err
+	}
+	serviceImpl := &WebhookSubscriptionServiceImpl{
+		logger:                    logger,
+		webhookSubscriptionConfig: webhookSubscriptionConfig,
+		httpClient:                &http.Client{Timeout: time.Duration(webhookSubscriptionConfig.DeliveryTimeoutInSecs) * time.Second},
+		subscriptions:             subscriptions,
+		deliveries:                make(map[string][]*common.WebhookDelivery),
+		deliveryQueue:             make(chan *webhookDeliveryJob, webhookSubscriptionConfig.DeliveryQueueSize),
+	}
+	for i := 0; i < webhookSubscriptionConfig.DeliveryWorkers; i++ {
+		go serviceImpl.deliver()
+	}
+	releaseNoteService.AddReleaseEventListener(serviceImpl)
+	return serviceImpl, nil
+}
+
+func (impl *WebhookSubscriptionServiceImpl) GetSubscriptions() []*common.WebhookSubscription {
+	subscriptions := impl.subscriptions.List()
+	for i, subscription := range subscriptions {
+		subscriptions[i] = withoutSecret(subscription)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].CreatedOn.Before(subscriptions[j].CreatedOn)
+	})
+	return subscriptions
+}
+
+func (impl *WebhookSubscriptionServiceImpl) GetSubscription(id string) (*common.WebhookSubscription, error) {
+	subscription, ok := impl.subscriptions.Get(id)
+	if !ok {
+		return nil, getSubscriptionNotFoundError(id)
+	}
+	return withoutSecret(subscription), nil
+}
+
+func (impl *WebhookSubscriptionServiceImpl) CreateSubscription(request *common.WebhookSubscriptionRequest) (*common.WebhookSubscription, error) {
+	if len(request.Secret) == 0 {
+		return nil, internalUtil.NewBadRequestError("secret is required")
+	}
+	err := validateSubscriptionRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	subscription := &common.WebhookSubscription{
+		Id:        uuid.New().String(),
+		Active:    request.Active == nil || *request.Active,
+		CreatedOn: now,
+	}
+	applySubscriptionRequest(subscription, request, now)
+	err = impl.subscriptions.Add(subscription)
+	if err != nil {
+		return nil, err
+	}
+	return withoutSecret(subscription), nil
+}
+
+func (impl *WebhookSubscriptionServiceImpl) UpdateSubscription(id string, request *common.WebhookSubscriptionRequest) (*common.WebhookSubscription, error) {
+	err := validateSubscriptionRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	subscription, err := impl.subscriptions.Update(id, func(existingSubscription *common.WebhookSubscription, found bool) (*common.WebhookSubscription, error) {
+		if !found {
+			return nil, getSubscriptionNotFoundError(id)
+		}
+		subscription := *existingSubscription
+		if request.Active != nil {
+			subscription.Active = *request.Active
+		}
+		applySubscriptionRequest(&subscription, request, time.Now())
+		return &subscription, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return withoutSecret(subscription), nil
+}
+
+func (impl *WebhookSubscriptionServiceImpl) DeleteSubscription(id string) error {
+	found, err := impl.subscriptions.Delete(id)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return getSubscriptionNotFoundError(id)
+	}
+	impl.mutex.Lock()
+	delete(impl.deliveries, id)
+	impl.mutex.Unlock()
+	return nil
+}
+
+func (impl *WebhookSubscriptionServiceImpl) GetDeliveries(id string) ([]*common.WebhookDelivery, error) {
+	if _, ok := impl.subscriptions.Get(id); !ok {
+		return nil, getSubscriptionNotFoundError(id)
+	}
+	impl.mutex.RLock()
+	defer impl.mutex.RUnlock()
+	history := impl.deliveries[id]
+	deliveries := make([]*common.WebhookDelivery, 0, len(history))
+	for i := len(history) - 1; i >= 0; i-- {
+		deliveries = append(deliveries, history[i])
+	}
+	return deliveries, nil
+}
+
+// OnReleaseEvent queues a delivery for every active subscription matching the event
+func (impl *WebhookSubscriptionServiceImpl) OnReleaseEvent(event *common.ReleaseEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		impl.logger.Errorw("error in marshaling release event", "eventId", event.Id, "err", err)
+		return
+	}
+	var jobs []*webhookDeliveryJob
+	for _, subscription := range impl.subscriptions.List() {
+		if !subscription.Active || !subscriptionMatchesEvent(subscription, event) {
+			continue
+		}
+		jobs = append(jobs, &webhookDeliveryJob{
+			deliveryId:     uuid.New().String(),
+			subscriptionId: subscription.Id,
+			event:          event,
+			payload:        payload,
+			attempt:        1,
+		})
+	}
+	for _, job := range jobs {
+		impl.enqueue(job)
+	}
+}
+
+func (impl *WebhookSubscriptionServiceImpl) enqueue(job *webhookDeliveryJob) {
+	select {
+	case impl.deliveryQueue <- job:
+	default:
+		impl.logger.Errorw("webhook delivery queue is full, dropping delivery", "subscriptionId", job.subscriptionId, "eventId", job.event.Id, "attempt", job.attempt)
+		impl.recordDelivery(job, &common.WebhookDelivery{Error: "delivery queue is full", AttemptedOn: time.Now()})
+	}
+}
+
+func (impl *WebhookSubscriptionServiceImpl) deliver() {
+	for job := range impl.deliveryQueue {
+		subscription, ok := impl.subscriptions.Get(job.subscriptionId)
+		if !ok || !subscription.Active {
+			// deleted or deactivated while the delivery was pending
+			continue
+		}
+		delivery, retryable := impl.post(subscription, job)
+		impl.recordDelivery(job, delivery)
+		if delivery.Success {
+			continue
+		}
+		impl.logger.Warnw("webhook delivery failed", "subscriptionId", job.subscriptionId, "eventId", job.event.Id, "attempt", job.attempt,
+			"statusCode", delivery.StatusCode, "err", delivery.Error)
+		if !retryable || job.attempt >= impl.webhookSubscriptionConfig.DeliveryMaxAttempts {
+			continue
+		}
+		// exponential backoff, the worker is not blocked while waiting
+		backoff := time.Duration(impl.webhookSubscriptionConfig.DeliveryBackoffInSecs) * time.Second << (job.attempt - 1)
+		retryJob := *job
+		retryJob.attempt++
+		time.AfterFunc(backoff, func() {
+			impl.enqueue(&retryJob)
+		})
+	}
+}
+
+// post sends the payload signed with the subscription secret, failures are retryable unless the target
+// rejected the request with a client error other than 408 and 429
+func (impl *WebhookSubscriptionServiceImpl) post(subscription *common.WebhookSubscription, job *webhookDeliveryJob) (*common.WebhookDelivery, bool) {
+	delivery := &common.WebhookDelivery{AttemptedOn: time.Now()}
+	request, err := http.NewRequest(http.MethodPost, subscription.Url, bytes.NewReader(job.payload))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery, false
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("User-Agent", "devtron-central-api")
+	request.Header.Set(WebhookEventHeader, job.event.Type)
+	request.Header.Set(WebhookDeliveryHeader, job.deliveryId)
+	request.Header.Set(WebhookSignatureHeader, "sha256="+getPayloadSignature(job.payload, subscription.Secret))
+	response, err := impl.httpClient.Do(request)
+	delivery.DurationMs = time.Since(delivery.AttemptedOn).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery, true
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(response.Body, 64*1024))
+	delivery.StatusCode = response.StatusCode
+	delivery.Success = response.StatusCode >= 200 && response.StatusCode < 300
+	if !delivery.Success {
+		delivery.Error = fmt.Sprintf("unexpected status %d", response.StatusCode)
+	}
+	retryable := response.StatusCode >= 500 || response.StatusCode == http.StatusRequestTimeout || response.StatusCode == http.StatusTooManyRequests
+	return delivery, retryable
+}
+
+func (impl *WebhookSubscriptionServiceImpl) recordDelivery(job *webhookDeliveryJob, delivery *common.WebhookDelivery) {
+	delivery.Id = job.deliveryId
+	delivery.SubscriptionId = job.subscriptionId
+	delivery.EventId = job.event.Id
+	delivery.Event = job.event.Type
+	delivery.Attempt = job.attempt
+	impl.mutex.Lock()
+	defer impl.mutex.Unlock()
+	// checked under the lock so that deliveries of a deleted subscription are not recorded after DeleteSubscription
+	if _, ok := impl.subscriptions.Get(job.subscriptionId); !ok {
+		return
+	}
+	history := append(impl.deliveries[job.subscriptionId], delivery)
+	if historySize := impl.webhookSubscriptionConfig.DeliveryHistorySize; len(history) > historySize {
+		history = history[len(history)-historySize:]
+	}
+	impl.deliveries[job.subscriptionId] = history
+}
+
+func getPayloadSignature(payload []byte, secret string) string {
+	hash := hmac.New(sha256.New, []byte(secret))
+	hash.Write(payload)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func subscriptionMatchesEvent(subscription *common.WebhookSubscription, event *common.ReleaseEvent) bool {
+	if len(subscription.Events) > 0 && !containsString(subscription.Events, event.Type) {
+		return false
+	}
+	if len(subscription.Repositories) > 0 && !containsString(subscription.Repositories, event.Repository) {
+		return false
+	}
+	if len(subscription.Channels) > 0 {
+		for _, channel := range event.Release.Channels {
+			if containsString(subscription.Channels, channel) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func validateSubscriptionRequest(request *common.WebhookSubscriptionRequest) error {
+	targetUrl, err := url.Parse(request.Url)
+	if err != nil || (targetUrl.Scheme != "http" && targetUrl.Scheme != "https") || len(targetUrl.Host) == 0 {
+		return internalUtil.NewBadRequestError(fmt.Sprintf("invalid url %s, an absolute http or https url is required", request.Url))
+	}
+	for _, event := range request.Events {
+		if !bean.IsValidReleaseEventType(event) {
+			return internalUtil.NewBadRequestError(fmt.Sprintf("unknown event %s", event))
+		}
+	}
+	return nil
+}
+
+func applySubscriptionRequest(subscription *common.WebhookSubscription, request *common.WebhookSubscriptionRequest, now time.Time) {
+	subscription.Url = request.Url
+	subscription.Events = request.Events
+	subscription.Repositories = request.Repositories
+	subscription.Channels = request.Channels
+	if len(request.Secret) > 0 {
+		subscription.Secret = request.Secret
+	}
+	subscription.UpdatedOn = now
+}
+
+func withoutSecret(subscription *common.WebhookSubscription) *common.WebhookSubscription {
+	subscriptionCopy := *subscription
+	subscriptionCopy.Secret = ""
+	return &subscriptionCopy
+}
+
+func getSubscriptionNotFoundError(id string) error {
+	return internalUtil.NewNotFoundError(fmt.Sprintf("webhook subscription %s not found", id))
+}
@@ -17,6 +17,108 @@ const ActionEdited = "edited"
 const EventTypeRelease = "release"
 const TimeFormatLayout = "2006-01-02T15:04:05Z"
 const PrerequisitesMatcher = "<!--upgrade-prerequisites-required-->"
+const PrerequisitesFenceInfo = "upgrade-prerequisites"
+const MandatoryUpgradeMatcher = "<!--upgrade-mandatory-->"
+
+// BreakingChangesMatcher and DeprecationsMatcher enclose blocks of a release body like PrerequisitesMatcher,
+// every top level list item of a block is an item
+const BreakingChangesMatcher = "<!--breaking-changes-->"
+const DeprecationsMatcher = "<!--deprecations-->"
+
+// ReleaseMetadataMatcher opens an html comment of a release body holding the release metadata as yaml,
+// the comment is closed by ReleaseMetadataEndMatcher
+const ReleaseMetadataMatcher = "<!--release-metadata"
+const ReleaseMetadataEndMatcher = "-->"
+const ReleaseMetadataDateLayout = "2006-01-02"
+
+// DefaultReleaseChannel is used when no channel is requested, it holds all published non pre-releases
+const DefaultReleaseChannel = ""
+
+// event types notified to subscribers of release changes
+const (
+	ReleaseEventPublished = "release.published"
+	ReleaseEventEdited    = "release.edited"
+)
+
+func IsValidReleaseEventType(eventType string) bool {
+	return eventType == ReleaseEventPublished || eventType == ReleaseEventEdited
+}
+
+// event types streamed on /events in addition to release events
+const (
+	// CatalogUpdatedEvent is raised when the releases of a repository change, also on sync from blob storage
+	CatalogUpdatedEvent = "catalog.updated"
+	// MetadataReloadedEvent is raised when ci build metadata is reloaded from its files
+	MetadataReloadedEvent = "metadata.reloaded"
+)
+
+func IsValidStreamEventType(eventType string) bool {
+	return IsValidReleaseEventType(eventType) || eventType == CatalogUpdatedEvent || eventType == MetadataReloadedEvent
+}
+
+// types of in-product announcements
+const (
+	AnnouncementInfo        = "info"
+	AnnouncementMaintenance = "maintenance"
+	AnnouncementWebinar     = "webinar"
+	AnnouncementDeprecation = "deprecation"
+)
+
+func IsValidAnnouncementType(announcementType string) bool {
+	return announcementType == AnnouncementInfo || announcementType == AnnouncementMaintenance ||
+		announcementType == AnnouncementWebinar || announcementType == AnnouncementDeprecation
+}
+
+// reasons of a feature flag evaluation, see FeatureFlagService
+const (
+	FeatureFlagReasonDisabled    = "disabled"
+	FeatureFlagReasonRuleMatched = "rule_matched"
+	FeatureFlagReasonDefault     = "default"
+	FeatureFlagReasonNotFound    = "not_found"
+)
+
+// sinks heartbeats of installations are stored in, see TELEMETRY_SINK
+const (
+	TelemetrySinkFile     = "file"
+	TelemetrySinkPostgres = "postgres"
+)
+
+// states of the github release webhook of a repository, see WebhookRegistrationService
+const (
+	WebhookRegistrationPending = "pending"
+	WebhookRegistrationInSync  = "in_sync"
+	WebhookRegistrationCreated = "created"
+	WebhookRegistrationUpdated = "updated"
+	WebhookRegistrationFailed  = "failed"
+)
+
+// severity of an upgrade check, see UpgradeCheckService
+const (
+	UpgradeSeverityNone     = "none"
+	UpgradeSeverityLow      = "low"
+	UpgradeSeverityMedium   = "medium"
+	UpgradeSeverityHigh     = "high"
+	UpgradeSeverityCritical = "critical"
+)
+
+type ReleaseNoteFormat string
+
+const (
+	ReleaseNoteFormatMarkdown ReleaseNoteFormat = "markdown"
+	ReleaseNoteFormatHtml     ReleaseNoteFormat = "html"
+	ReleaseNoteFormatText     ReleaseNoteFormat = "text"
+)
+
+func IsValidReleaseNoteFormat(format ReleaseNoteFormat) bool {
+	return format == ReleaseNoteFormatMarkdown || format == ReleaseNoteFormatHtml || format == ReleaseNoteFormatText
+}
+
+type ReleaseFeedFormat string
+
+const (
+	ReleaseFeedFormatAtom ReleaseFeedFormat = "atom"
+	ReleaseFeedFormatRss  ReleaseFeedFormat = "rss"
+)
 
 const (
 	CACHE_KEY    = "latest"
@@ -34,14 +34,108 @@ func InitializeApp() (*App, error) {
 		return nil, err
 	}
 	blobStorageServiceImpl := blob_storage.NewBlobStorageServiceImpl(sugaredLogger)
-	releaseNoteServiceImpl, err := pkg.NewReleaseNoteServiceImpl(sugaredLogger, gitHubClient, moduleConfig, blobConfigVariables, blobStorageServiceImpl)
+	releaseChannelConfig, err := util.NewReleaseChannelConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	releaseChannelServiceImpl, err := pkg.NewReleaseChannelServiceImpl(sugaredLogger, releaseChannelConfig, gitHubClient)
+	if err != nil {
+		return nil, err
+	}
+	releaseNoteServiceImpl, err := pkg.NewReleaseNoteServiceImpl(sugaredLogger, gitHubClient, moduleConfig, blobConfigVariables, blobStorageServiceImpl, releaseChannelServiceImpl)
 	if err != nil {
 		return nil, err
 	}
 	webhookSecretValidatorImpl := pkg.NewWebhookSecretValidatorImpl(sugaredLogger, gitHubClient)
-	ciBuildMetadataServiceImpl := pkg.NewCiBuildMetadataServiceImpl(sugaredLogger)
-	restHandlerImpl := api.NewRestHandlerImpl(sugaredLogger, releaseNoteServiceImpl, webhookSecretValidatorImpl, gitHubClient, ciBuildMetadataServiceImpl)
-	muxRouter := api.NewMuxRouter(sugaredLogger, restHandlerImpl)
+	ciBuildMetadataConfig, err := util.NewCiBuildMetadataConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	ciBuildMetadataServiceImpl := pkg.NewCiBuildMetadataServiceImpl(sugaredLogger, ciBuildMetadataConfig)
+	releaseNoteRendererImpl := pkg.NewReleaseNoteRendererImpl(sugaredLogger, gitHubClient)
+	rolloutConfig, err := util.NewRolloutConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	rolloutServiceImpl, err := pkg.NewRolloutServiceImpl(sugaredLogger, rolloutConfig, releaseNoteServiceImpl)
+	if err != nil {
+		return nil, err
+	}
+	releaseSearchServiceImpl := pkg.NewReleaseSearchServiceImpl(sugaredLogger, releaseNoteServiceImpl, rolloutServiceImpl)
+	releaseFeedServiceImpl := pkg.NewReleaseFeedServiceImpl(sugaredLogger, gitHubClient, releaseNoteServiceImpl, releaseNoteRendererImpl, rolloutServiceImpl)
+	releaseManifestConfig, err := util.NewReleaseManifestConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	releaseManifestServiceImpl := pkg.NewReleaseManifestServiceImpl(sugaredLogger, gitHubClient, releaseManifestConfig)
+	releaseCompareConfig, err := util.NewReleaseCompareConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	releaseCompareServiceImpl := pkg.NewReleaseCompareServiceImpl(sugaredLogger, gitHubClient, releaseCompareConfig)
+	eventStreamConfig, err := util.NewEventStreamConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	eventStreamServiceImpl := pkg.NewEventStreamServiceImpl(sugaredLogger, eventStreamConfig, releaseNoteServiceImpl, ciBuildMetadataServiceImpl)
+	upgradeCheckServiceImpl := pkg.NewUpgradeCheckServiceImpl(sugaredLogger, releaseNoteServiceImpl, releaseChannelServiceImpl, rolloutServiceImpl)
+	securityAdvisoryConfig, err := util.NewSecurityAdvisoryConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	securityAdvisoryServiceImpl, err := pkg.NewSecurityAdvisoryServiceImpl(sugaredLogger, gitHubClient, securityAdvisoryConfig, releaseNoteServiceImpl)
+	if err != nil {
+		return nil, err
+	}
+	announcementConfig, err := util.NewAnnouncementConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	announcementServiceImpl, err := pkg.NewAnnouncementServiceImpl(sugaredLogger, announcementConfig)
+	if err != nil {
+		return nil, err
+	}
+	featureFlagConfig, err := util.NewFeatureFlagConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	featureFlagServiceImpl, err := pkg.NewFeatureFlagServiceImpl(sugaredLogger, featureFlagConfig)
+	if err != nil {
+		return nil, err
+	}
+	telemetryConfig, err := util.NewTelemetryConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	telemetrySink, err := pkg.NewTelemetrySink(sugaredLogger, telemetryConfig)
+	if err != nil {
+		return nil, err
+	}
+	telemetryServiceImpl := pkg.NewTelemetryServiceImpl(sugaredLogger, telemetryConfig, telemetrySink, releaseNoteServiceImpl)
+	restHandlerImpl := api.NewRestHandlerImpl(sugaredLogger, releaseNoteServiceImpl, webhookSecretValidatorImpl, gitHubClient, ciBuildMetadataServiceImpl, releaseChannelServiceImpl, releaseNoteRendererImpl, releaseSearchServiceImpl, releaseFeedServiceImpl, releaseManifestServiceImpl, releaseCompareServiceImpl, eventStreamServiceImpl, eventStreamConfig, upgradeCheckServiceImpl, securityAdvisoryServiceImpl, announcementServiceImpl, featureFlagServiceImpl, telemetryServiceImpl, telemetryConfig, rolloutServiceImpl)
+	adminConfig, err := util.NewAdminConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	adminTokenValidatorImpl := pkg.NewAdminTokenValidatorImpl(sugaredLogger, adminConfig)
+	webhookSubscriptionConfig, err := util.NewWebhookSubscriptionConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	webhookSubscriptionServiceImpl, err := pkg.NewWebhookSubscriptionServiceImpl(sugaredLogger, webhookSubscriptionConfig, releaseNoteServiceImpl)
+	if err != nil {
+		return nil, err
+	}
+	webhookRegistrationConfig, err := util.NewWebhookRegistrationConfig(sugaredLogger)
+	if err != nil {
+		return nil, err
+	}
+	webhookRegistrationServiceImpl, err := pkg.NewWebhookRegistrationServiceImpl(sugaredLogger, gitHubClient, webhookRegistrationConfig)
+	if err != nil {
+		return nil, err
+	}
+	adminRestHandlerImpl := api.NewAdminRestHandlerImpl(sugaredLogger, adminTokenValidatorImpl, webhookSubscriptionServiceImpl, webhookRegistrationServiceImpl, announcementServiceImpl, telemetryServiceImpl, rolloutServiceImpl)
+	muxRouter := api.NewMuxRouter(sugaredLogger, restHandlerImpl, adminRestHandlerImpl)
 	app := NewApp(muxRouter, sugaredLogger)
 	return app, nil
 }